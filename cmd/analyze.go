@@ -1,19 +1,98 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/harsh-apk/groot/internal/analyzer"
+	"github.com/harsh-apk/groot/internal/cache"
+	"github.com/harsh-apk/groot/internal/git"
 	"github.com/harsh-apk/groot/internal/model"
 	"github.com/spf13/cobra"
 )
 
+// formatExtensions maps each output format to the extension of the file it is written to.
+var formatExtensions = map[string]string{
+	"txt":      "txt",
+	"json":     "json",
+	"skeleton": "txt",
+	"ndjson":   "ndjson",
+}
+
+// redactMode controls secret redaction: "auto" redacts the skeleton format
+// only, which holds file content; the others still print secrets found in
+// annotations, routes and dependency versions. "always" and "never" force it
+// on or off for every format.
+var redactMode string
+
+// noDefaultIgnores disables groot's built-in ignore patterns.
+var noDefaultIgnores bool
+
+// gitTracked builds the tree from the git index instead of the file system.
+var gitTracked bool
+
+// sinceRef and stagedOnly restrict the analysis to files changed in git.
+var (
+	sinceRef   string
+	stagedOnly bool
+)
+
+// noCache disables the on-disk parse cache.
+var noCache bool
+
+// analysisTimeout bounds the whole run and fileTimeout the parse of each file.
+var (
+	analysisTimeout time.Duration
+	fileTimeout     time.Duration
+)
+
+// maxFileSize and hideSkipped control which files are left unparsed and
+// whether they are listed.
+var (
+	maxFileSize int64
+	hideSkipped bool
+)
+
+// complexityTop and complexityThreshold configure the complexity report.
+var (
+	complexityTop       int
+	complexityThreshold int
+)
+
+// duplicateSimilarity configures duplicate detection.
+var duplicateSimilarity float64
+
+// moduleName restricts the analysis to one module of a monorepo.
+var moduleName string
+
+// blameAnnotations adds git blame authors and dates to TODO-style comments.
+var blameAnnotations bool
+
+// historyWindow is the window of git history to summarize, e.g. 90d.
+var historyWindow string
+
+// strictMode makes the command fail when any diagnostics were reported.
+var strictMode bool
+
+// streamOutput writes the output incrementally while files are parsed.
+var streamOutput bool
+
+// streamWriters creates the incremental writer of each format that supports --stream.
+var streamWriters = map[string]func(io.Writer) analyzer.StreamWriter{
+	"txt":    analyzer.NewTextStreamWriter,
+	"ndjson": analyzer.NewNDJSONWriter,
+}
+
 // This struct will hold the answers from the interactive survey.
 type analysisAnswers struct {
 	Path            string
@@ -28,6 +107,12 @@ var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
 	Short: "Analyzes a codebase through an interactive session.",
 	Run: func(cmd *cobra.Command, args []string) {
+		// --since and --staged list the changed files themselves, which
+		// would silently replace the list of tracked files.
+		if gitTracked && (sinceRef != "" || stagedOnly) {
+			fmt.Fprintln(os.Stderr, "Error: --git-tracked cannot be combined with --since or --staged")
+			os.Exit(1)
+		}
 		answers, err := askAnalysisQuestions()
 		if err != nil {
 			// This can happen if the user cancels (e.g., Ctrl+C).
@@ -39,29 +124,73 @@ var analyzeCmd = &cobra.Command{
 		skipList := processStringList(answers.SkipDirs)
 		includeList := processStringList(answers.IncludeExts)
 
-		fmt.Println("\n🔍 Starting analysis...")
-		rootNode, stats, err := analyzer.Analyze(answers.Path, skipList, includeList)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error during analysis: %v\n", err)
+		newStreamWriter, streamable := streamWriters[answers.Format]
+		if streamOutput && !streamable {
+			fmt.Fprintf(os.Stderr, "Error: --stream is not supported for the %s format\n", answers.Format)
 			os.Exit(1)
 		}
-		fmt.Println("✅ Analysis complete!")
 
-		var finalOutput []byte
+		// Progress messages must not end up in the records when piping ndjson.
+		progress := os.Stdout
+		if answers.Format == "ndjson" && answers.OutputFileName == "" {
+			progress = os.Stderr
+		}
+		fmt.Fprintln(progress, "\n🔍 Starting analysis...")
+		redactSecrets, err := shouldRedact(redactMode, answers.Format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if blameAnnotations {
+			requireRepo(answers.Path, "--blame")
+		}
+		if historyWindow != "" {
+			requireRepo(answers.Path, "--history")
+		}
+		opts := analyzer.Options{
+			Skeleton:            answers.Format == "skeleton",
+			Redact:              redactSecrets,
+			NoDefaultIgnores:    noDefaultIgnores,
+			GitTracked:          gitTracked,
+			Since:               sinceRef,
+			Staged:              stagedOnly,
+			CacheDir:            parseCacheDir(noCache),
+			Version:             version + "-" + commit,
+			FileTimeout:         fileTimeout,
+			MaxFileSize:         maxFileSize,
+			HideSkipped:         hideSkipped,
+			ComplexityTop:       complexityTop,
+			ComplexityThreshold: complexityThreshold,
+			DuplicateSimilarity: duplicateSimilarity,
+			Module:              moduleName,
+			Blame:               blameAnnotations,
+			History:             historyWindow,
+		}
+		ctx, stop := analysisContext()
+		defer stop()
 
-		// Format the output based on the user's choice.
-		if answers.Format == "json" {
-			result := model.AnalysisResult{Root: rootNode, Analytics: stats}
-			finalOutput, _ = json.MarshalIndent(result, "", "  ")
+		// ndjson exists to be consumed while the analysis runs, so it always streams.
+		if streamOutput || answers.Format == "ndjson" {
+			streamAnalysis(ctx, answers, skipList, includeList, opts, newStreamWriter)
+			return
+		}
+		result, err := analyzer.Analyze(ctx, answers.Path, skipList, includeList, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error during analysis: %v\n", err)
+			os.Exit(1)
+		}
+		if result.Incomplete {
+			fmt.Fprintf(os.Stderr, "⚠️  Analysis %s; writing partial results.\n", result.IncompleteReason)
 		} else {
-			treeOutput, analyticsOutput := analyzer.FormatText(rootNode, stats, includeList)
-			finalOutput = []byte(treeOutput + analyticsOutput + time.Now().Format("\n\nLast Analysis completed at: 2006-01-02 15:04:05"))
+			fmt.Println("✅ Analysis complete!")
 		}
 
+		finalOutput := renderResult(result, answers.Format, includeList)
+
 		// --- UPDATED: Write to file or print to console ---
 		if answers.OutputFileName != "" {
 			// Automatically add the correct file extension.
-			fullFileName := fmt.Sprintf("%s.%s", answers.OutputFileName, answers.Format)
+			fullFileName := fmt.Sprintf("%s.%s", answers.OutputFileName, formatExtensions[answers.Format])
 			fullPath := filepath.Join(answers.OutputDirectory, fullFileName)
 
 			// Ensure the output directory exists.
@@ -80,9 +209,158 @@ var analyzeCmd = &cobra.Command{
 		} else {
 			fmt.Println(string(finalOutput))
 		}
+		reportDiagnostics(result)
+		if result.Incomplete || (strictMode && len(result.Diagnostics) > 0) {
+			os.Exit(1)
+		}
 	},
 }
 
+func init() {
+	analyzeCmd.Flags().StringVar(&redactMode, "redact", "auto", "Redact secrets in the output: auto (skeleton only; other formats may show secrets), always or never.")
+	analyzeCmd.Flags().BoolVar(&noDefaultIgnores, "no-default-ignores", false, "Disable the built-in ignore patterns (node_modules, dist, build, ...); .gitignore and .grootignore still apply.")
+	analyzeCmd.Flags().BoolVar(&gitTracked, "git-tracked", false, "Only analyze files tracked in the local git repository's index.")
+	analyzeCmd.Flags().StringVar(&sinceRef, "since", "", "Only analyze files changed relative to this git ref and show how their elements changed.")
+	analyzeCmd.Flags().BoolVar(&stagedOnly, "staged", false, "Only analyze changes staged in the index (against --since, or HEAD).")
+	analyzeCmd.Flags().BoolVar(&streamOutput, "stream", false, "Write the output while files are parsed, keeping memory bounded on very large trees (txt; ndjson always streams).")
+	analyzeCmd.Flags().DurationVar(&analysisTimeout, "timeout", 0, "Stop the analysis after this long and write partial results (e.g. 2m); 0 means no limit.")
+	analyzeCmd.Flags().DurationVar(&fileTimeout, "file-timeout", 10*time.Second, "Skip files that take longer than this to parse; 0 means no limit.")
+	analyzeCmd.Flags().Int64Var(&maxFileSize, "max-file-size", analyzer.DefaultMaxFileSize, "Skip files larger than this many bytes; 0 means no limit.")
+	analyzeCmd.Flags().BoolVar(&hideSkipped, "hide-skipped", false, "Leave binary, generated, minified and oversized files out of the tree instead of marking them.")
+	analyzeCmd.Flags().IntVar(&complexityTop, "top-complex", analyzer.DefaultComplexityTop, "Number of most complex functions to list in the report; 0 leaves the list out.")
+	analyzeCmd.Flags().IntVar(&complexityThreshold, "complexity-threshold", analyzer.DefaultComplexityThreshold, "Flag functions whose cognitive complexity reaches this value as hotspots; 0 disables it.")
+	analyzeCmd.Flags().Float64Var(&duplicateSimilarity, "duplicate-similarity", analyzer.DefaultDuplicateSimilarity, "Report functions whose bodies are at least this similar (0-1) as near copies; 0 disables duplicate detection.")
+	analyzeCmd.Flags().StringVar(&moduleName, "module", "", "Only analyze this module of a monorepo (by name or directory) and the local modules it depends on.")
+	analyzeCmd.Flags().BoolVar(&blameAnnotations, "blame", false, "Add the author and date of each TODO, FIXME, HACK, XXX and deprecation comment from the local git blame.")
+	analyzeCmd.Flags().StringVar(&historyWindow, "history", "", "Summarize the local git history over this window (e.g. 90d, 12w, 6m, 1y or all): commits, last change, top authors and lines changed per file, and the hottest files by churn × complexity.")
+	analyzeCmd.Flags().BoolVar(&strictMode, "strict", false, "Exit with status 1 if any file could not be read or parsed cleanly.")
+	analyzeCmd.Flags().BoolVar(&noCache, "no-cache", false, "Parse every file again instead of reusing results cached by earlier runs.")
+}
+
+// streamAnalysis runs a streamed analysis, writing the output file or the
+// console as each file is parsed.
+func streamAnalysis(ctx context.Context, answers *analysisAnswers, skipList, includeList []string, opts analyzer.Options, newWriter func(io.Writer) analyzer.StreamWriter) {
+	out := os.Stdout
+	fullPath := ""
+	if answers.OutputFileName != "" {
+		fullPath = filepath.Join(answers.OutputDirectory, fmt.Sprintf("%s.%s", answers.OutputFileName, formatExtensions[answers.Format]))
+		if err := os.MkdirAll(answers.OutputDirectory, os.ModePerm); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output directory %s: %v\n", answers.OutputDirectory, err)
+			os.Exit(1)
+		}
+		file, err := os.Create(fullPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing to file %s: %v\n", fullPath, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	result, err := analyzer.Stream(ctx, answers.Path, skipList, includeList, opts, newWriter(out))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error during analysis: %v\n", err)
+		os.Exit(1)
+	}
+	if answers.Format == "txt" {
+		fmt.Fprintln(out, time.Now().Format("\n\nLast Analysis completed at: 2006-01-02 15:04:05"))
+	}
+	if fullPath != "" {
+		fmt.Printf("\nOutput successfully written to %s\n", fullPath)
+	}
+	reportDiagnostics(result)
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "⚠️  Analysis stopped early; the output is partial.")
+		os.Exit(1)
+	}
+	if strictMode && len(result.Diagnostics) > 0 {
+		os.Exit(1)
+	}
+}
+
+// reportDiagnostics tells the user on stderr that some files had problems,
+// which are detailed in the output itself.
+func reportDiagnostics(result *model.AnalysisResult) {
+	if len(result.Diagnostics) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d problem(s) found while analyzing; see Diagnostics in the output.\n", len(result.Diagnostics))
+	}
+}
+
+// analysisContext returns the context of an analysis run: it is cancelled by
+// Ctrl+C, after which a second Ctrl+C exits immediately, and by --timeout.
+func analysisContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cancel := stop
+	if analysisTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, analysisTimeout)
+		cancel = func() {
+			cancelTimeout()
+			stop()
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, cancel
+}
+
+// renderResult formats an analysis in the chosen output format.
+func renderResult(result *model.AnalysisResult, format string, includeList []string) []byte {
+	if format == "json" {
+		output, _ := json.MarshalIndent(result, "", "  ")
+		return output
+	}
+	if format == "ndjson" {
+		var buf bytes.Buffer
+		analyzer.WriteNDJSON(&buf, result)
+		return buf.Bytes()
+	}
+	treeOutput, analyticsOutput := analyzer.FormatText(result, includeList)
+	if format == "skeleton" {
+		treeOutput += analyzer.FormatSkeleton(result.Root)
+	}
+	return []byte(treeOutput + analyticsOutput + time.Now().Format("\n\nLast Analysis completed at: 2006-01-02 15:04:05"))
+}
+
+// requireRepo exits with an error when path is not inside a git repository,
+// which flag needs.
+func requireRepo(path, flag string) {
+	if repo, err := git.FindRepo(path); err != nil || repo == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s needs %s to be inside a git repository\n", flag, path)
+		os.Exit(1)
+	}
+}
+
+// parseCacheDir returns the directory of the parse cache, or "" when it is
+// disabled or unavailable.
+func parseCacheDir(disabled bool) string {
+	if disabled {
+		return ""
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// shouldRedact resolves the --redact flag for the chosen output format.
+// Auto redaction covers the skeleton format alone.
+func shouldRedact(mode, format string) (bool, error) {
+	switch mode {
+	case "auto":
+		return format == "skeleton", nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid --redact value %q (expected auto, always or never)", mode)
+	}
+}
+
 // askAnalysisQuestions defines and runs the interactive survey.
 func askAnalysisQuestions() (*analysisAnswers, error) {
 	// Get the current working directory as the default path.
@@ -116,9 +394,9 @@ func askAnalysisQuestions() (*analysisAnswers, error) {
 			Name: "format",
 			Prompt: &survey.Select{
 				Message: "Choose an output format:",
-				Options: []string{"txt", "json"},
+				Options: []string{"txt", "json", "ndjson", "skeleton"},
 				Default: "txt",
-				Help:    "Choose 'txt' for a human-readable report or 'json' for machine-readable output.",
+				Help:    "Choose 'txt' for a human-readable report, 'json' for machine-readable output, 'ndjson' for one JSON record per file written as files are parsed, or 'skeleton' to include every source file with function bodies elided.",
 			},
 		},
 		{
@@ -133,7 +411,7 @@ func askAnalysisQuestions() (*analysisAnswers, error) {
 			Name: "outputFileName",
 			Prompt: &survey.Input{
 				Message: "Enter a base file name (press Enter to print to console):",
-				Help:    "The result will be saved here. The correct extension (.txt or .json) will be added automatically.",
+				Help:    "The result will be saved here. The correct extension (.txt, .json or .ndjson) will be added automatically.",
 			},
 		},
 	}
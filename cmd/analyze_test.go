@@ -0,0 +1,31 @@
+package cmd
+
+import "testing"
+
+func TestShouldRedact(t *testing.T) {
+	tests := []struct {
+		mode, format string
+		want         bool
+		wantErr      bool
+	}{
+		{"auto", "skeleton", true, false},
+		{"auto", "txt", false, false},
+		{"auto", "json", false, false},
+		{"auto", "ndjson", false, false},
+		{"always", "txt", true, false},
+		{"always", "skeleton", true, false},
+		{"never", "skeleton", false, false},
+		{"never", "json", false, false},
+		{"sometimes", "txt", false, true},
+	}
+	for _, tt := range tests {
+		got, err := shouldRedact(tt.mode, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("shouldRedact(%q, %q) error = %v, want error %v", tt.mode, tt.format, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("shouldRedact(%q, %q) = %v, want %v", tt.mode, tt.format, got, tt.want)
+		}
+	}
+}
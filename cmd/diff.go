@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/harsh-apk/groot/internal/analyzer"
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// Flags of the diff command.
+var (
+	diffRef     string
+	diffFormat  string
+	diffOutput  string
+	diffSkip    string
+	diffInclude string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [old.json new.json | path]",
+	Short: "Shows the structural changes between two analyses.",
+	Long: `Compares two analyses and reports files added and removed, code elements
+added, removed, moved or with changed signatures, lines of code per language
+and dependency edges that appeared or disappeared.
+
+Pass two JSON files written by 'groot analyze', or use --ref to compare the
+working tree at path (default: current directory) with a git ref. Only files
+tracked by git are compared; untracked files are left out.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if diffRef != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if diffFormat != "txt" && diffFormat != "json" {
+			fmt.Fprintf(os.Stderr, "Error: invalid --format value %q (expected txt or json)\n", diffFormat)
+			os.Exit(1)
+		}
+
+		var oldResult, newResult *model.AnalysisResult
+		var err error
+		if diffRef != "" {
+			path := "."
+			if len(args) == 1 {
+				path = args[0]
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			oldResult, newResult, err = analyzeAgainstRef(ctx, path, diffRef)
+			stop()
+		} else {
+			oldResult, err = loadAnalysis(args[0])
+			if err == nil {
+				newResult, err = loadAnalysis(args[1])
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		diff := analyzer.Compare(oldResult, newResult)
+		if diffRef != "" {
+			// The old side was analyzed in a temporary checkout; name it by its ref instead.
+			diff.OldRoot = diffRef
+		}
+
+		var output []byte
+		if diffFormat == "json" {
+			output, _ = json.MarshalIndent(diff, "", "  ")
+		} else {
+			output = []byte(analyzer.FormatDiff(diff))
+		}
+
+		if diffOutput == "" {
+			fmt.Println(string(output))
+			return
+		}
+		if err := os.WriteFile(diffOutput, output, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing to file %s: %v\n", diffOutput, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Diff successfully written to %s\n", diffOutput)
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffRef, "ref", "", "Compare the working tree with this git ref instead of two JSON files.")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "txt", "Output format: txt or json.")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "Write the diff to this file instead of the console.")
+	diffCmd.Flags().StringVar(&diffSkip, "skip", "", "Directories to skip when analyzing with --ref (comma-separated).")
+	diffCmd.Flags().StringVar(&diffInclude, "include", "", "File extensions to include when analyzing with --ref (comma-separated).")
+}
+
+// loadAnalysis reads an AnalysisResult written by 'groot analyze' in JSON format.
+func loadAnalysis(path string) (*model.AnalysisResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	var result model.AnalysisResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+	if result.Root == nil {
+		return nil, fmt.Errorf("%s does not contain a groot analysis", path)
+	}
+	return &result, nil
+}
+
+// analyzeAgainstRef analyzes the tree at path as it is at ref, using a
+// temporary checkout, and as it is in the working tree. Both sides only see
+// tracked files, so untracked files in the working tree are not reported as
+// added.
+func analyzeAgainstRef(ctx context.Context, path, ref string) (*model.AnalysisResult, *model.AnalysisResult, error) {
+	tmpDir, err := os.MkdirTemp("", "groot-diff-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := git.ExportTree(ctx, path, ref, tmpDir); err != nil {
+		return nil, nil, fmt.Errorf("could not check out %s: %w", ref, err)
+	}
+
+	skipList := processStringList(diffSkip)
+	includeList := processStringList(diffInclude)
+	oldResult, err := analyzer.Analyze(ctx, tmpDir, skipList, includeList, analyzer.Options{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not analyze %s: %w", ref, err)
+	}
+	newResult, err := analyzer.Analyze(ctx, path, skipList, includeList, analyzer.Options{GitTracked: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not analyze %s: %w", path, err)
+	}
+	// A partial analysis would show the files it missed as changes.
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("analysis stopped: %w", err)
+	}
+	return oldResult, newResult, nil
+}
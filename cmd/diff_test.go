@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/analyzer"
+)
+
+func TestAnalyzeAgainstRefOfCleanTreeIsEmpty(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, ".config"))
+	files := map[string]string{
+		"main.go":       "package main\n\nfunc main() {}\n",
+		"vendor/x/x.go": "package x\n\nfunc X() {}\n",
+		"build/b.go":    "package build\n\nfunc B() {}\n",
+		".gitignore":    "*.tmp\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	oldResult, newResult, err := analyzeAgainstRef(context.Background(), root, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := analyzer.Compare(oldResult, newResult)
+	if len(diff.FilesAdded) > 0 || len(diff.FilesRemoved) > 0 || len(diff.ElementsAdded) > 0 || len(diff.ElementsRemoved) > 0 {
+		t.Errorf("diff of a clean tree against HEAD = %+v, want it empty", diff)
+	}
+	for lang, delta := range diff.LanguageDeltas {
+		if delta.OldFiles != delta.NewFiles || delta.OldLOC != delta.NewLOC {
+			t.Errorf("%s changed from %d files to %d against HEAD", lang, delta.OldFiles, delta.NewFiles)
+		}
+	}
+}
@@ -92,7 +92,7 @@ func Execute() {
 	// This line disables Cobra's default 'completion' command for a cleaner interface.
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	// Add the new versionCmd to the list of commands.
-	rootCmd.AddCommand(analyzeCmd, aboutCmd, contributeCmd, versionCmd)
+	rootCmd.AddCommand(analyzeCmd, diffCmd, watchCmd, aboutCmd, contributeCmd, versionCmd)
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -109,6 +109,8 @@ func PrintIntro() {
 	color.Yellow("Usage:")
 	fmt.Println("  Run 'groot' to start the interactive analysis session.")
 	fmt.Println("  Or use one of the following commands:")
+	fmt.Printf("  %-12s  %s\n", color.CyanString("diff"), "Compare two analyses or the tree against a git ref.")
+	fmt.Printf("  %-12s  %s\n", color.CyanString("watch"), "Keep an overview file up to date as files change.")
 	fmt.Printf("  %-12s  %s\n", color.CyanString("about"), "Learn more about the Groot tool.")
 	fmt.Printf("  %-12s  %s\n", color.CyanString("contribute"), "Find out how to contribute.")
 	fmt.Printf("  %-12s  %s\n", color.CyanString("version"), "Show the application version.")
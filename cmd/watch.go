@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/harsh-apk/groot/internal/analyzer"
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// Flags of the watch command.
+var (
+	watchOutput   string
+	watchFormat   string
+	watchSkip     string
+	watchInclude  string
+	watchDebounce time.Duration
+	watchRedact   string
+	watchNoCache  bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Keeps an overview file up to date while you edit.",
+	Long: `Analyzes path (default: current directory) once, writes the overview to
+--output, then watches the tree and rewrites the file whenever a file changes.
+Only changed files are parsed again, and the same ignore rules as 'groot
+analyze' decide which changes matter. Stop it with Ctrl+C.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, ok := formatExtensions[watchFormat]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: invalid --format value %q (expected txt, json, ndjson or skeleton)\n", watchFormat)
+			os.Exit(1)
+		}
+		redactSecrets, err := shouldRedact(watchRedact, watchFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		absRoot, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		output, err := filepath.Abs(watchOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Neither the output file nor the cache may trigger the rewrite that
+		// produced them.
+		cacheDir := parseCacheDir(watchNoCache)
+		skipList := append(processStringList(watchSkip), outputIgnorePatterns(absRoot, output, cacheDir)...)
+		includeList := processStringList(watchInclude)
+		opts := analyzer.Options{
+			Skeleton:            watchFormat == "skeleton",
+			Redact:              redactSecrets,
+			CacheDir:            cacheDir,
+			Version:             version + "-" + commit,
+			MaxFileSize:         analyzer.DefaultMaxFileSize,
+			ComplexityTop:       analyzer.DefaultComplexityTop,
+			ComplexityThreshold: analyzer.DefaultComplexityThreshold,
+			DuplicateSimilarity: analyzer.DefaultDuplicateSimilarity,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		result, err := analyzer.Analyze(ctx, absRoot, skipList, includeList, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error during analysis: %v\n", err)
+			os.Exit(1)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err := writeAtomically(output, renderResult(result, watchFormat, includeList)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing to file %s: %v\n", output, err)
+			os.Exit(1)
+		}
+
+		watcher, err := watch.New()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer watcher.Close()
+		if err := watcher.Sync(directoryPaths(result.Root)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		fmt.Printf("👀 Watching %s, writing to %s (Ctrl+C to stop)\n", absRoot, output)
+
+		skip := func(path string) bool { return isOwnFile(path, output, opts.CacheDir) }
+		for {
+			changed, rescanAll, ok := collectChanges(ctx, watcher.Events, watcher.Errors, watchDebounce, skip)
+			if !ok {
+				fmt.Println("\nStopped watching.")
+				return
+			}
+			opts.Previous, opts.Changed = result, changed
+			if rescanAll {
+				opts.Previous, opts.Changed = nil, nil
+			}
+			next, err := analyzer.Analyze(ctx, absRoot, skipList, includeList, opts)
+			if ctx.Err() != nil {
+				fmt.Println("\nStopped watching.")
+				return
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: analysis failed: %v\n", err)
+				continue
+			}
+			if rescanAll || affectsAnalysis(result.Root, next.Root, changed) {
+				if err := writeAtomically(output, renderResult(next, watchFormat, includeList)); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not write %s: %v\n", output, err)
+				} else {
+					fmt.Printf("%s Updated %s\n", time.Now().Format("15:04:05"), output)
+				}
+			}
+			if err := watcher.Sync(directoryPaths(next.Root)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			result = next
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().StringVarP(&watchOutput, "output", "o", "", "File to keep up to date (required).")
+	watchCmd.Flags().StringVar(&watchFormat, "format", "txt", "Output format: txt, json, ndjson or skeleton.")
+	watchCmd.Flags().StringVar(&watchSkip, "skip", "", "Directories to skip (comma-separated).")
+	watchCmd.Flags().StringVar(&watchInclude, "include", "", "File extensions to include (comma-separated).")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 300*time.Millisecond, "How long to wait for changes to settle before rewriting the output.")
+	watchCmd.Flags().StringVar(&watchRedact, "redact", "auto", "Redact secrets in the output: auto (skeleton only; other formats may show secrets), always or never.")
+	watchCmd.Flags().BoolVar(&watchNoCache, "no-cache", false, "Parse every file again instead of reusing results cached by earlier runs.")
+	watchCmd.MarkFlagRequired("output")
+}
+
+// outputIgnorePatterns returns skip patterns for the output file, its
+// temporary sibling and the cache directory when they live inside the
+// watched tree. An empty cacheDir means caching is off.
+func outputIgnorePatterns(root, output, cacheDir string) []string {
+	var patterns []string
+	if relPath, ok := pathBelow(root, output); ok {
+		pattern := "/" + filepath.ToSlash(relPath)
+		patterns = append(patterns, pattern, pattern+".tmp")
+	}
+	if relPath, ok := pathBelow(root, cacheDir); ok && cacheDir != "" && relPath != "." {
+		patterns = append(patterns, "/"+filepath.ToSlash(relPath)+"/")
+	}
+	return patterns
+}
+
+// pathBelow returns path relative to root, and whether it lies inside it.
+func pathBelow(root, path string) (string, bool) {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return relPath, true
+}
+
+// isOwnFile reports whether a changed path is one watch writes itself: the
+// output file, its temporary sibling or anything in the cache directory.
+func isOwnFile(path, output, cacheDir string) bool {
+	if path == output || path == output+".tmp" {
+		return true
+	}
+	if cacheDir == "" {
+		return false
+	}
+	_, ok := pathBelow(cacheDir, path)
+	return ok
+}
+
+// collectChanges waits for changed paths and returns them once no event has
+// arrived for delay. Paths that skip reports are dropped. rescanAll is set
+// when the watcher lost events or failed, since any file may then have
+// changed. ok is false when ctx is done first.
+func collectChanges(ctx context.Context, events <-chan string, errs <-chan error, delay time.Duration, skip func(string) bool) (changed map[string]bool, rescanAll, ok bool) {
+	changed = make(map[string]bool)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, false
+		case path := <-events:
+			if skip(path) {
+				continue
+			}
+			changed[path] = true
+			debounce = time.After(delay)
+		case err := <-errs:
+			if !errors.Is(err, watch.ErrOverflow) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			rescanAll = true
+			debounce = time.After(delay)
+		case <-debounce:
+			return changed, rescanAll, true
+		}
+	}
+}
+
+// writeAtomically replaces path with content through a rename, so readers
+// never see a half-written file.
+func writeAtomically(path string, content []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// directoryPaths lists the directories of a tree, which are the ones to watch.
+func directoryPaths(node *model.Node) []string {
+	if !node.IsDir {
+		return nil
+	}
+	dirs := []string{node.Path}
+	for _, child := range node.Children {
+		dirs = append(dirs, directoryPaths(child)...)
+	}
+	return dirs
+}
+
+// affectsAnalysis reports whether any changed path belongs to the old or new
+// tree, so changes to ignored files do not rewrite the output.
+func affectsAnalysis(oldRoot, newRoot *model.Node, changed map[string]bool) bool {
+	oldPaths, newPaths := treePaths(oldRoot), treePaths(newRoot)
+	if len(oldPaths) != len(newPaths) {
+		return true
+	}
+	for path := range newPaths {
+		if !oldPaths[path] {
+			return true
+		}
+	}
+	for path := range changed {
+		if newPaths[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// treePaths collects the paths of every node of a tree.
+func treePaths(node *model.Node) map[string]bool {
+	paths := make(map[string]bool)
+	var visit func(*model.Node)
+	visit = func(n *model.Node) {
+		paths[n.Path] = true
+		for _, child := range n.Children {
+			visit(child)
+		}
+	}
+	visit(node)
+	return paths
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harsh-apk/groot/internal/analyzer"
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/watch"
+)
+
+func TestOutputIgnorePatterns(t *testing.T) {
+	root := filepath.FromSlash("/src/project")
+	tests := []struct {
+		name     string
+		output   string
+		cacheDir string
+		want     []string
+	}{
+		{"output inside", "/src/project/docs/tree.txt", "", []string{"/docs/tree.txt", "/docs/tree.txt.tmp"}},
+		{"output outside", "/tmp/tree.txt", "", nil},
+		{"output in sibling", "/src/project2/tree.txt", "", nil},
+		{"cache inside", "/tmp/tree.txt", "/src/project/.cache/groot", []string{"/.cache/groot/"}},
+		{"cache outside", "/tmp/tree.txt", "/home/u/.cache/groot", nil},
+		{"both inside", "/src/project/tree.txt", "/src/project/cache", []string{"/tree.txt", "/tree.txt.tmp", "/cache/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := tt.cacheDir
+			if cacheDir != "" {
+				cacheDir = filepath.FromSlash(cacheDir)
+			}
+			got := outputIgnorePatterns(root, filepath.FromSlash(tt.output), cacheDir)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outputIgnorePatterns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOwnFile(t *testing.T) {
+	output := filepath.FromSlash("/src/project/tree.txt")
+	cacheDir := filepath.FromSlash("/src/project/.cache/groot")
+	tests := []struct {
+		path     string
+		cacheDir string
+		want     bool
+	}{
+		{"/src/project/tree.txt", cacheDir, true},
+		{"/src/project/tree.txt.tmp", cacheDir, true},
+		{"/src/project/.cache/groot/0123.json", cacheDir, true},
+		{"/src/project/.cache/groot", cacheDir, true},
+		{"/src/project/.cache/other.json", cacheDir, false},
+		{"/src/project/main.go", cacheDir, false},
+		{"/src/project/tree.txt.bak", cacheDir, false},
+		{"/src/project/.cache/groot/0123.json", "", false},
+	}
+	for _, tt := range tests {
+		if got := isOwnFile(filepath.FromSlash(tt.path), output, tt.cacheDir); got != tt.want {
+			t.Errorf("isOwnFile(%q, cache %q) = %v, want %v", tt.path, tt.cacheDir, got, tt.want)
+		}
+	}
+}
+
+// TestOwnFilesStayOutOfTheTree checks that the output and the cache, written
+// inside the watched tree, are not analyzed and so cannot trigger rewrites.
+func TestOwnFilesStayOutOfTheTree(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, ".config"))
+	output := filepath.Join(root, "tree.txt")
+	cacheDir := filepath.Join(root, "cache", "groot")
+	files := map[string]string{
+		"main.go":          "package main\n\nfunc main() {}\n",
+		"tree.txt":         "old output\n",
+		"tree.txt.tmp":     "new output\n",
+		"cache/groot/c.go": "package c\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := analyzer.Analyze(context.Background(), root, outputIgnorePatterns(root, output, cacheDir), nil, analyzer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := treePaths(result.Root)
+	if !paths[filepath.Join(root, "main.go")] {
+		t.Errorf("main.go is missing from the tree")
+	}
+	for _, own := range []string{output, output + ".tmp", cacheDir, filepath.Join(cacheDir, "c.go")} {
+		if paths[own] {
+			t.Errorf("%s is part of the analyzed tree", own)
+		}
+	}
+}
+
+func TestAffectsAnalysis(t *testing.T) {
+	tree := func(files ...string) *model.Node {
+		root := &model.Node{Path: "/p", IsDir: true}
+		for _, file := range files {
+			root.Children = append(root.Children, &model.Node{Path: "/p/" + file})
+		}
+		return root
+	}
+	tests := []struct {
+		name     string
+		old, new *model.Node
+		changed  []string
+		want     bool
+	}{
+		{"tracked file changed", tree("a.go", "b.go"), tree("a.go", "b.go"), []string{"/p/a.go"}, true},
+		{"ignored file changed", tree("a.go"), tree("a.go"), []string{"/p/node_modules/x.js", "/p/tree.txt"}, false},
+		{"file added", tree("a.go"), tree("a.go", "b.go"), []string{"/p/b.go"}, true},
+		{"file removed", tree("a.go", "b.go"), tree("a.go"), []string{"/p/b.go"}, true},
+		{"file renamed", tree("a.go"), tree("b.go"), nil, true},
+		{"nothing changed", tree("a.go"), tree("a.go"), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := make(map[string]bool)
+			for _, path := range tt.changed {
+				changed[path] = true
+			}
+			if got := affectsAnalysis(tt.old, tt.new, changed); got != tt.want {
+				t.Errorf("affectsAnalysis() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectChangesDebounces(t *testing.T) {
+	events := make(chan string, 8)
+	errs := make(chan error, 1)
+	skip := func(path string) bool { return path == "/p/tree.txt" }
+
+	// Events arriving closer together than the delay are batched.
+	go func() {
+		for _, path := range []string{"/p/a.go", "/p/tree.txt", "/p/b.go", "/p/a.go"} {
+			events <- path
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	changed, rescanAll, ok := collectChanges(context.Background(), events, errs, 200*time.Millisecond, skip)
+	if !ok || rescanAll {
+		t.Fatalf("collectChanges() ok = %v, rescanAll = %v, want true, false", ok, rescanAll)
+	}
+	want := map[string]bool{"/p/a.go": true, "/p/b.go": true}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("collectChanges() = %v, want %v", changed, want)
+	}
+}
+
+func TestCollectChangesRescansAfterOverflow(t *testing.T) {
+	events := make(chan string)
+	errs := make(chan error, 1)
+	errs <- watch.ErrOverflow
+	_, rescanAll, ok := collectChanges(context.Background(), events, errs, 10*time.Millisecond, func(string) bool { return false })
+	if !ok || !rescanAll {
+		t.Errorf("collectChanges() ok = %v, rescanAll = %v, want true, true", ok, rescanAll)
+	}
+}
+
+func TestCollectChangesStopsWithContext(t *testing.T) {
+	events := make(chan string, 1)
+	// Only skipped events arrive, so nothing ever starts the debounce.
+	events <- "/p/tree.txt.tmp"
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, ok := collectChanges(ctx, events, make(chan error), 10*time.Millisecond, func(string) bool { return true })
+	if ok {
+		t.Errorf("collectChanges() ok = true after the context was done")
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("collectChanges() returned before the context was done")
+	}
+}
@@ -1,7 +1,8 @@
 package analyzer
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,8 +12,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/harsh-apk/groot/internal/cache"
+	"github.com/harsh-apk/groot/internal/git"
 	"github.com/harsh-apk/groot/internal/model"
-	"github.com/harsh-apk/groot/internal/parser"
 	"github.com/harsh-apk/groot/internal/walker"
 )
 
@@ -32,16 +34,120 @@ func GetLanguageByFileExtension(filePath string) (model.Language, bool) {
 	return model.Language{}, false
 }
 
+// Options holds the optional behaviour of an analysis run.
+type Options struct {
+	// Skeleton keeps a copy of each source file with function bodies elided.
+	Skeleton bool
+	// Redact replaces secrets in element names, routes, skeletons and
+	// dependency versions with placeholders.
+	Redact bool
+	// NoDefaultIgnores disables the walker's built-in ignore patterns.
+	NoDefaultIgnores bool
+	// GitTracked restricts the analysis to files in the git index.
+	GitTracked bool
+	// Since restricts the analysis to files changed relative to this git ref
+	// and reports how their elements changed.
+	Since string
+	// Staged analyzes the changes staged in the index, against Since or HEAD.
+	Staged bool
+	// Previous is an earlier analysis of the same tree, run with the same
+	// options. Files not listed in Changed keep its results instead of being
+	// read and parsed again.
+	Previous *model.AnalysisResult
+	// Changed holds the absolute paths of the files modified since Previous.
+	Changed map[string]bool
+	// CacheDir is where parse results are kept between runs; empty disables
+	// the cache.
+	CacheDir string
+	// Version identifies the groot build, invalidating caches of other builds.
+	Version string
+	// FileTimeout limits how long a single file may take to parse; zero
+	// means no limit.
+	FileTimeout time.Duration
+	// MaxFileSize is the size in bytes above which files are skipped; zero
+	// means no limit.
+	MaxFileSize int64
+	// HideSkipped leaves binary, generated, minified and oversized files out
+	// of the output instead of marking them.
+	HideSkipped bool
+	// ComplexityTop is how many of the most complex functions the report
+	// lists; zero leaves the ranking out.
+	ComplexityTop int
+	// ComplexityThreshold is the cognitive complexity at which functions
+	// are flagged as hotspots; zero flags none.
+	ComplexityThreshold int
+	// DuplicateSimilarity is the share of winnowing hashes two function
+	// bodies must have in common to be reported as near copies; zero turns
+	// duplicate detection off.
+	DuplicateSimilarity float64
+	// Module restricts the analysis to the module of that name or
+	// directory and the local modules it depends on.
+	Module string
+	// Blame adds the author and date of each TODO, FIXME and similar
+	// annotation from git blame. It is ignored with Staged.
+	Blame bool
+	// History is the window of git history, such as 90d, 6m, 1y or all,
+	// summarized per file and used to rank hot files; empty skips it.
+	History string
+}
+
+// collector gathers the findings reported by concurrent workers.
+type collector struct {
+	mu          sync.Mutex
+	redactions  []model.Redaction
+	diagnostics []model.Diagnostic
+}
+
+// addRedactions records redactions made in a single file.
+func (c *collector) addRedactions(redactions []model.Redaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redactions = append(c.redactions, redactions...)
+}
+
 // Analyze performs the core analysis and returns the raw data structures.
-func Analyze(rootPath string, skipDirs []string, includeExts []string) (*model.Node, model.Analytics, error) {
+// When ctx is done before the analysis finishes, the files reached so far
+// are returned in a result marked as incomplete.
+func Analyze(ctx context.Context, rootPath string, skipDirs []string, includeExts []string, opts Options) (*model.AnalysisResult, error) {
 	startTime := time.Now()
 
-	rootNode, err := walker.BuildFileTree(rootPath, skipDirs)
-	if err != nil {
-		return nil, model.Analytics{}, fmt.Errorf("failed to walk file tree: %w", err)
+	rootNode, err := walker.BuildFileTree(ctx, rootPath, skipDirs, walker.Options{
+		NoDefaultIgnores: opts.NoDefaultIgnores,
+		GitTracked:       opts.GitTracked,
+		Since:            opts.Since,
+		Staged:           opts.Staged,
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("failed to walk file tree: %w", err)
 	}
 
 	allFileNodes := collectFileNodes(rootNode)
+
+	// Manifests are read first, even when their extension is not included,
+	// to find the modules of the tree.
+	var findings collector
+	manifests := newManifestSet(&findings, opts.Redact)
+	for _, node := range allFileNodes {
+		manifests.add(node)
+	}
+	modules := newModuleSet(rootNode.Path, manifests.list())
+	modules.annotate(rootNode)
+	var selected map[string]bool
+	if opts.Module != "" {
+		if selected, err = modules.selectModule(opts.Module); err != nil {
+			return nil, err
+		}
+		pruneModules(rootNode, selected)
+		allFileNodes = collectFileNodes(rootNode)
+	}
+	history, err := loadHistory(ctx, rootNode.Path, opts, &findings)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range allFileNodes {
+		history.annotate(node)
+	}
+
 	var filteredFileNodes []*model.Node
 
 	if len(includeExts) > 0 {
@@ -60,60 +166,231 @@ func Analyze(rootPath string, skipDirs []string, includeExts []string) (*model.N
 	}
 
 	var wg sync.WaitGroup
+	base := newChangeBase(rootNode.Path, opts)
+	previous := newPreviousAnalysis(opts)
+	store := openCache(rootNode.Path, opts, base)
 	jobs := make(chan *model.Node, len(filteredFileNodes))
 	for w := 0; w < runtime.NumCPU(); w++ {
 		wg.Add(1)
-		go worker(&wg, jobs)
+		go worker(ctx, &wg, jobs, opts, base, previous, store, &findings)
 	}
 	for _, node := range filteredFileNodes {
 		jobs <- node
 	}
 	close(jobs)
 	wg.Wait()
+	// An interrupted run has not looked up every file, and saving would drop
+	// their entries from the cache.
+	if store != nil && ctx.Err() == nil {
+		if err := store.Save(); err != nil {
+			findings.addDiagnostics(model.Diagnostic{Phase: PhaseCache, Kind: KindIO, Message: err.Error()})
+		}
+	}
 
 	stats := aggregateAnalytics(allFileNodes, filteredFileNodes)
+	ranking := newComplexityRanking(opts)
+	duplicates := newDuplicateIndex(opts, 0)
+	tests := newTestIndex(rootNode.Path)
+	entries := newEntryIndex(manifests.list())
+	endpoints := newAPISurface()
+	debt := newTechDebt()
+	for _, node := range filteredFileNodes {
+		ranking.add(node)
+		duplicates.add(node)
+		tests.add(node)
+		entries.add(node)
+		endpoints.add(node)
+		debt.add(node)
+		history.add(node)
+	}
+	if opts.HideSkipped {
+		pruneSkipped(rootNode)
+	}
 	stats.Duration = time.Since(startTime)
 	stats.DurationReadable = stats.Duration.Round(time.Millisecond).String()
 	stats.FilesScanned = len(allFileNodes)
 
-	return rootNode, stats, nil
+	result := &model.AnalysisResult{
+		Root:         rootNode,
+		Analytics:    stats,
+		Complexity:   ranking.report(),
+		Duplicates:   duplicates.clusters(),
+		Tests:        tests.report(),
+		Dependencies: modules.manifests(manifests.list(), selected),
+		Modules:      modules.list(selected),
+		EntryPoints:  entries.list(),
+		APISurface:   endpoints.list(),
+		TechDebt:     debt.list(),
+		History:      history.result(),
+	}
+	if base != nil {
+		result.Changes = summarizeChanges(base, filteredFileNodes)
+	}
+	sortRedactions(findings.redactions)
+	result.Redactions = findings.redactions
+	sortDiagnostics(findings.diagnostics)
+	result.Diagnostics = findings.diagnostics
+	markIncomplete(ctx, result)
+	return result, nil
+}
+
+// markIncomplete flags a result whose analysis was stopped by ctx.
+func markIncomplete(ctx context.Context, result *model.AnalysisResult) {
+	switch err := ctx.Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		result.Incomplete, result.IncompleteReason = true, "timed out"
+	case err != nil:
+		result.Incomplete, result.IncompleteReason = true, "interrupted"
+	}
 }
 
 // FormatText takes the raw analysis data and generates the human-readable string outputs.
 // Note: The calling function in cmd/analyze.go should be updated to pass 'includeExts'.
-func FormatText(rootNode *model.Node, stats model.Analytics, includeExts []string) (string, string) {
+func FormatText(result *model.AnalysisResult, includeExts []string) (string, string) {
 	var treeBuilder strings.Builder
-	absPath, _ := filepath.Abs(rootNode.Path)
+	absPath, _ := filepath.Abs(result.Root.Path)
 	treeBuilder.WriteString(fmt.Sprintf("Codebase overview for: %s\n\n", absPath))
-	formatTree(&treeBuilder, rootNode, "", true, includeExts)
+	if len(result.EntryPoints) > 0 {
+		appendEntryPoints(&treeBuilder, result.Root.Path, result.EntryPoints)
+	}
+	formatTree(&treeBuilder, result.Root, "", true, includeExts)
 
-	var analyticsBuilder strings.Builder
-	appendAnalytics(&analyticsBuilder, stats)
+	return treeBuilder.String(), formatReport(result)
+}
 
-	return treeBuilder.String(), analyticsBuilder.String()
+// formatReport renders the analytics and summary sections that follow the tree.
+func formatReport(result *model.AnalysisResult) string {
+	var builder strings.Builder
+	appendAnalytics(&builder, result.Analytics)
+	if result.Changes != nil {
+		appendChanges(&builder, result.Changes)
+	}
+	if result.Complexity != nil {
+		appendComplexity(&builder, result.Root.Path, result.Complexity)
+	}
+	if result.History != nil {
+		appendHistory(&builder, result.Root.Path, result.History)
+	}
+	if len(result.Duplicates) > 0 {
+		appendDuplicates(&builder, result.Root.Path, result.Duplicates)
+	}
+	if len(result.APISurface) > 0 {
+		appendAPISurface(&builder, result.Root.Path, result.APISurface)
+	}
+	if len(result.Modules) > 1 {
+		appendModules(&builder, result.Root.Path, result.Modules, result.Analytics)
+	}
+	if result.Tests != nil {
+		appendTests(&builder, result.Root.Path, result.Analytics, result.Tests)
+	}
+	if len(result.TechDebt) > 0 {
+		appendTechDebt(&builder, result.Root.Path, result.TechDebt)
+	}
+	if len(result.Dependencies) > 0 {
+		appendDependencies(&builder, result.Root.Path, result.Dependencies)
+	}
+	if len(result.Redactions) > 0 {
+		appendRedactions(&builder, result.Root.Path, result.Redactions)
+	}
+	if len(result.Diagnostics) > 0 {
+		appendDiagnostics(&builder, result.Root.Path, result.Diagnostics)
+	}
+	if result.Incomplete {
+		appendIncomplete(&builder, result.IncompleteReason)
+	}
+	return builder.String()
+}
+
+// FormatSkeleton renders the skeleton of every parsed file as fenced code blocks,
+// in the same order the files appear in the tree.
+func FormatSkeleton(rootNode *model.Node) string {
+	var builder strings.Builder
+	builder.WriteString("\n\n---\n\n")
+	builder.WriteString("🦴 Code Skeleton\n")
+	for _, node := range collectFileNodes(rootNode) {
+		if node.Skeleton == "" {
+			continue
+		}
+		lang, _ := GetLanguageByFileExtension(node.Path)
+		relPath := relativePath(rootNode.Path, node.Path)
+		builder.WriteString(fmt.Sprintf("\n%s\n```%s\n", relPath, strings.ToLower(lang.Name)))
+		builder.WriteString(node.Skeleton)
+		if !strings.HasSuffix(node.Skeleton, "\n") {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("```\n")
+	}
+	return builder.String()
 }
 
 // worker is a concurrent worker that parses file nodes.
-func worker(wg *sync.WaitGroup, jobs <-chan *model.Node) {
+func worker(ctx context.Context, wg *sync.WaitGroup, jobs <-chan *model.Node, opts Options, base *changeBase, previous *previousAnalysis, store *cache.Cache, findings *collector) {
 	defer wg.Done()
 	for node := range jobs {
-		lang, supported := GetLanguageByFileExtension(node.Path)
-		if !supported {
-			continue
+		processNode(ctx, node, opts, base, previous, store, findings)
+	}
+}
+
+// processNode parses a single file node, compares it with the change base
+// and redacts it, as configured by opts. Once ctx is done, files are skipped.
+func processNode(ctx context.Context, node *model.Node, opts Options, base *changeBase, previous *previousAnalysis, store *cache.Cache, findings *collector) {
+	if ctx.Err() != nil || previous.reuse(node, findings) {
+		return
+	}
+	lang, supported := GetLanguageByFileExtension(node.Path)
+	if !supported {
+		// Content from a git revision is not on disk; only changed files are
+		// listed then, so they are not worth sniffing.
+		if base == nil {
+			node.Skipped = sniffFile(node, opts)
 		}
-		content, err := os.ReadFile(node.Path)
+		return
+	}
+	if node.Status != git.StatusDeleted {
+		syntaxErrors, err := parseNode(ctx, node, lang, opts, base, store)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not read file %s: %v\n", node.Path, err)
-			continue
+			if ctx.Err() == nil {
+				findings.addDiagnostics(diagnosticFor(node.Path, err))
+			}
+			return
 		}
-		node.LOC = bytes.Count(content, []byte("\n")) + 1
-		elements, err := parser.Parse(content, lang)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not parse file %s: %v\n", node.Path, err)
-			continue
+		if node.Skipped != "" {
+			return
+		}
+		tagTests(node, lang)
+		dropShadowed(node)
+		markHotspots(node, opts.ComplexityThreshold)
+		if opts.Blame && !opts.Staged && len(node.Annotations) > 0 {
+			if err := blameAnnotations(ctx, node); err != nil && ctx.Err() == nil {
+				findings.addDiagnostics(model.Diagnostic{Path: node.Path, Phase: PhaseBlame, Kind: KindGit, Message: err.Error()})
+			}
 		}
-		node.CodeElements = elements
+		if len(syntaxErrors) > 0 {
+			findings.addDiagnostics(syntaxDiagnostic(node.Path, syntaxErrors))
+		}
+	}
+	if base != nil {
+		if err := base.compare(ctx, node, lang); err != nil && ctx.Err() == nil {
+			findings.addDiagnostics(model.Diagnostic{
+				Path:    node.Path,
+				Phase:   PhaseCompare,
+				Kind:    KindGit,
+				Message: fmt.Sprintf("could not compare with %s: %v", base.ref, err),
+			})
+		}
+	}
+	if opts.Redact {
+		findings.addRedactions(redactNode(node))
+	}
+}
+
+// readContent returns the content of a file node, taking it from the git
+// index when analyzing staged changes.
+func readContent(ctx context.Context, node *model.Node, base *changeBase) ([]byte, error) {
+	if base != nil {
+		return base.readCurrent(ctx, node)
 	}
+	return os.ReadFile(node.Path)
 }
 
 // aggregateAnalytics processes file nodes to build the analytics summary.
@@ -122,29 +399,51 @@ func aggregateAnalytics(allNodes, parsedNodes []*model.Node) model.Analytics {
 		PerLanguageStats: make(map[string]model.LanguageStats),
 	}
 	for _, node := range parsedNodes {
-		lang, supported := GetLanguageByFileExtension(node.Path)
-		if !supported {
-			continue
-		}
-		if node.LOC > 0 {
-			stats.FilesParsed++
-			stats.TotalLOC += node.LOC
-		}
-		stats.TotalElements += len(node.CodeElements)
-		langStats, ok := stats.PerLanguageStats[lang.Name]
-		if !ok {
-			langStats = model.LanguageStats{ElementCounts: make(map[string]int)}
-		}
-		langStats.FileCount++
-		langStats.LOC += node.LOC
-		for _, el := range node.CodeElements {
-			langStats.ElementCounts[el.Type]++
-		}
-		stats.PerLanguageStats[lang.Name] = langStats
+		addFileStats(&stats, node)
 	}
 	return stats
 }
 
+// addFileStats adds a single analyzed file to the analytics summary.
+func addFileStats(stats *model.Analytics, node *model.Node) {
+	if node.Skipped != "" {
+		stats.FilesSkipped++
+		return
+	}
+	lang, supported := GetLanguageByFileExtension(node.Path)
+	if !supported || node.Status == git.StatusDeleted {
+		return
+	}
+	if node.LOC > 0 {
+		stats.FilesParsed++
+		stats.TotalLOC += node.LOC
+		stats.TotalCodeLines += node.CodeLines
+		stats.TotalCommentLines += node.CommentLines
+		stats.TotalBlankLines += node.BlankLines
+	}
+	stats.TotalElements += len(node.CodeElements)
+	tests := countTests(node)
+	if isTestFile(node.Path, lang.Name) {
+		stats.TestFiles++
+	}
+	stats.Tests += tests
+	langStats, ok := stats.PerLanguageStats[lang.Name]
+	if !ok {
+		langStats = model.LanguageStats{ElementCounts: make(map[string]int)}
+	}
+	langStats.FileCount++
+	langStats.LOC += node.LOC
+	langStats.CodeLines += node.CodeLines
+	langStats.CommentLines += node.CommentLines
+	langStats.BlankLines += node.BlankLines
+	langStats.Tests += tests
+	for _, el := range node.CodeElements {
+		langStats.ElementCounts[el.Type]++
+	}
+	stats.PerLanguageStats[lang.Name] = langStats
+	addModuleStats(stats, node, lang.Name)
+}
+
 // appendAnalytics formats and writes the analytics summary.
 func appendAnalytics(builder *strings.Builder, stats model.Analytics) {
 	builder.WriteString("\n\n---\n\n")
@@ -154,17 +453,26 @@ func appendAnalytics(builder *strings.Builder, stats model.Analytics) {
 	builder.WriteString(fmt.Sprintf("%-20s %s\n", "Analysis Duration:", stats.DurationReadable))
 	builder.WriteString(fmt.Sprintf("%-20s %d\n", "Files Scanned:", stats.FilesScanned))
 	builder.WriteString(fmt.Sprintf("%-20s %d\n", "Files Parsed:", stats.FilesParsed))
+	if stats.FilesSkipped > 0 {
+		builder.WriteString(fmt.Sprintf("%-20s %d\n", "Files Skipped:", stats.FilesSkipped))
+	}
 	builder.WriteString(fmt.Sprintf("%-20s %d\n", "Total Lines of Code:", stats.TotalLOC))
 	builder.WriteString(fmt.Sprintf("%-20s %d\n", "Total Elements Found:", stats.TotalElements))
+	if stats.TestFiles > 0 || stats.Tests > 0 {
+		builder.WriteString(fmt.Sprintf("%-20s %d\n", "Test Files:", stats.TestFiles))
+		builder.WriteString(fmt.Sprintf("%-20s %d\n", "Tests:", stats.Tests))
+	}
 	builder.WriteString("────────────────────────────────────────\n\n")
-	builder.WriteString("Language Breakdown\n")
-	builder.WriteString("────────────────────────────────────────\n")
 
 	sortedLangs := make([]string, 0, len(stats.PerLanguageStats))
 	for langName := range stats.PerLanguageStats {
 		sortedLangs = append(sortedLangs, langName)
 	}
 	sort.Strings(sortedLangs)
+	appendLineCounts(builder, stats, sortedLangs)
+
+	builder.WriteString("Language Breakdown\n")
+	builder.WriteString("────────────────────────────────────────\n")
 
 	for _, langName := range sortedLangs {
 		langStats := stats.PerLanguageStats[langName]
@@ -183,6 +491,55 @@ func appendAnalytics(builder *strings.Builder, stats model.Analytics) {
 	}
 }
 
+// appendLineCounts writes a cloc-style table of blank, comment and code
+// lines per language.
+func appendLineCounts(builder *strings.Builder, stats model.Analytics, sortedLangs []string) {
+	builder.WriteString("Line Counts\n")
+	builder.WriteString("────────────────────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%-16s %7s %9s %9s %9s\n", "Language", "files", "blank", "comment", "code"))
+	builder.WriteString("────────────────────────────────────────────────────────\n")
+	files := 0
+	for _, langName := range sortedLangs {
+		langStats := stats.PerLanguageStats[langName]
+		files += langStats.FileCount
+		builder.WriteString(fmt.Sprintf("%-16s %7d %9d %9d %9d\n", langName, langStats.FileCount,
+			langStats.BlankLines, langStats.CommentLines, langStats.CodeLines))
+	}
+	builder.WriteString("────────────────────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%-16s %7d %9d %9d %9d\n", "Total", files,
+		stats.TotalBlankLines, stats.TotalCommentLines, stats.TotalCodeLines))
+	builder.WriteString("────────────────────────────────────────────────────────\n\n")
+}
+
+// appendIncomplete warns that the report only covers part of the tree.
+func appendIncomplete(builder *strings.Builder, reason string) {
+	builder.WriteString("⚠️  Incomplete Analysis\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("The analysis %s before it finished; the tree and figures above are partial.\n\n", reason))
+}
+
+// appendRedactions lists every secret that was replaced by a placeholder.
+func appendRedactions(builder *strings.Builder, rootPath string, redactions []model.Redaction) {
+	builder.WriteString("🔒 Redactions\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d secret(s) were replaced with [REDACTED:<kind>] placeholders.\n", len(redactions)))
+	for _, r := range redactions {
+		relPath := relativePath(rootPath, r.Path)
+		builder.WriteString(fmt.Sprintf("  - %s:%d  %s\n", relPath, r.Line, r.Kind))
+	}
+	builder.WriteString("\n")
+}
+
+// relativePath returns path relative to root, or path itself when it has no
+// relative form, e.g. on another Windows drive.
+func relativePath(root, path string) string {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return relPath
+}
+
 // collectFileNodes recursively traverses the tree and returns a flat slice of all file nodes.
 func collectFileNodes(node *model.Node) []*model.Node {
 	var files []*model.Node
@@ -210,15 +567,22 @@ func formatTree(builder *strings.Builder, node *model.Node, prefix string, isRoo
 	if isRoot {
 		name = node.Path
 	}
-	builder.WriteString(name + "\n")
+	builder.WriteString(name + statusLabel(node) + skippedLabel(node) + moduleLabel(node) + "\n")
 
 	if !node.IsDir && len(node.CodeElements) > 0 {
 		sort.Slice(node.CodeElements, func(i, j int) bool {
 			return node.CodeElements[i].Line < node.CodeElements[j].Line
 		})
 		for _, el := range node.CodeElements {
-			builder.WriteString(fmt.Sprintf("%s  - %s: %s (L%d)\n", prefix, el.Type, el.Name, el.Line))
+			builder.WriteString(fmt.Sprintf("%s  %s\n", prefix, formatElement(el)))
+		}
+	}
+	// Every element of an added file is new, so only the element list is shown for it.
+	for _, change := range node.ElementChanges {
+		if node.Status == git.StatusAdded {
+			break
 		}
+		builder.WriteString(fmt.Sprintf("%s  %s\n", prefix, formatElementChange(change)))
 	}
 
 	for i, child := range node.Children {
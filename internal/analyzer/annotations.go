@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// annotationTags orders the tags of the tech debt list, most pressing first.
+var annotationTags = []string{"FIXME", "XXX", "HACK", "TODO", "DEPRECATED"}
+
+// tagRank returns the position of a tag in annotationTags.
+func tagRank(tag string) int {
+	for i, t := range annotationTags {
+		if t == tag {
+			return i
+		}
+	}
+	return len(annotationTags)
+}
+
+// blameAnnotations sets the author and date of the annotations of a file
+// node from git blame.
+func blameAnnotations(ctx context.Context, node *model.Node) error {
+	lines := make([]int, len(node.Annotations))
+	for i, annotation := range node.Annotations {
+		lines[i] = annotation.Line
+	}
+	blame, err := git.Blame(ctx, node.Path, lines)
+	if err != nil {
+		return err
+	}
+	for i := range node.Annotations {
+		if line, ok := blame[node.Annotations[i].Line]; ok {
+			node.Annotations[i].Author = line.Author
+			node.Annotations[i].Date = line.Time.Format("2006-01-02")
+		}
+	}
+	return nil
+}
+
+// techDebt collects the annotations of an analysis.
+type techDebt struct {
+	annotations []model.FileAnnotation
+}
+
+// newTechDebt returns an empty list.
+func newTechDebt() *techDebt {
+	return &techDebt{}
+}
+
+// add records the annotations of a file node.
+func (t *techDebt) add(node *model.Node) {
+	if node.Skipped != "" || node.Status == git.StatusDeleted {
+		return
+	}
+	for _, annotation := range node.Annotations {
+		t.annotations = append(t.annotations, model.FileAnnotation{File: node.Path, Annotation: annotation})
+	}
+}
+
+// list returns the annotations by tag, then directory, file and line.
+func (t *techDebt) list() []model.FileAnnotation {
+	sort.SliceStable(t.annotations, func(i, j int) bool {
+		a, b := t.annotations[i], t.annotations[j]
+		if ra, rb := tagRank(a.Tag), tagRank(b.Tag); ra != rb {
+			return ra < rb
+		}
+		if da, db := filepath.Dir(a.File), filepath.Dir(b.File); da != db {
+			return da < db
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return t.annotations
+}
+
+// appendTechDebt lists the annotations grouped by tag and directory.
+func appendTechDebt(builder *strings.Builder, rootPath string, annotations []model.FileAnnotation) {
+	builder.WriteString(fmt.Sprintf("🧾 Tech debt (%d annotations)\n", len(annotations)))
+	builder.WriteString("────────────────────────────────────────\n")
+	counts := make(map[string]int)
+	for _, a := range annotations {
+		counts[a.Tag]++
+	}
+	tag, dir := "", ""
+	for _, a := range annotations {
+		relPath := relativePath(rootPath, a.File)
+		if a.Tag != tag {
+			tag, dir = a.Tag, ""
+			builder.WriteString(fmt.Sprintf("▶ %s (%d)\n", tag, counts[tag]))
+		}
+		if relDir := filepath.Dir(relPath); relDir != dir {
+			dir = relDir
+			builder.WriteString(fmt.Sprintf("  %s/\n", filepath.ToSlash(dir)))
+		}
+		line := fmt.Sprintf("    - %s:%d", filepath.Base(relPath), a.Line)
+		if a.Element != "" {
+			line += " in " + a.Element
+		}
+		if a.Owner != "" {
+			line += fmt.Sprintf(" (%s)", a.Owner)
+		}
+		if a.Text != "" {
+			line += ": " + a.Text
+		}
+		if a.Author != "" {
+			line += fmt.Sprintf(" [%s, %s]", a.Author, a.Date)
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\n")
+}
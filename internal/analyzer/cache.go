@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/harsh-apk/groot/internal/cache"
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/parser"
+)
+
+// openCache loads the parse cache for the tree at root, or returns nil when
+// caching is disabled. Analyses of git revisions read content that is not on
+// disk, so they are never cached.
+func openCache(root string, opts Options, base *changeBase) *cache.Cache {
+	if opts.CacheDir == "" || base != nil {
+		return nil
+	}
+	return cache.Open(opts.CacheDir, root, cacheFingerprint(opts.Version))
+}
+
+// cacheFingerprint identifies the groot build, cache format and query set
+// that cached parse results were produced with.
+func cacheFingerprint(version string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00", version, cache.FormatVersion)
+	for _, lang := range CompiledLanguageConfig.Languages {
+		fmt.Fprintf(h, "%s\x00%q\x00%q\x00%q\x00", lang.Name, lang.Queries, lang.ImportQueries, lang.RouteQueries)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseNode fills in the parse results of a file node, taking them from the
+// cache when the file is unchanged and storing them there otherwise, and
+// returns the syntax errors found in the file. Parsing is limited to
+// opts.FileTimeout. Files that are too large, binary, generated or minified
+// are not parsed; node.Skipped says why. Errors are *fileError values.
+func parseNode(ctx context.Context, node *model.Node, lang model.Language, opts Options, base *changeBase, store *cache.Cache) ([]model.SyntaxError, error) {
+	var info os.FileInfo
+	if base == nil {
+		var err error
+		if info, err = os.Stat(node.Path); err != nil {
+			return nil, &fileError{phase: PhaseRead, kind: KindIO, err: err}
+		}
+		if tooLarge(info.Size(), opts) {
+			node.Skipped = SkipTooLarge
+			return nil, nil
+		}
+	}
+	if store != nil {
+		if entry, ok := store.Lookup(node.Path, info, opts.Skeleton); ok {
+			return applyEntry(node, entry, opts.Skeleton), nil
+		}
+	}
+
+	content, err := readContent(ctx, node, base)
+	if err != nil {
+		return nil, &fileError{phase: PhaseRead, kind: KindIO, err: err}
+	}
+	if tooLarge(int64(len(content)), opts) {
+		node.Skipped = SkipTooLarge
+		return nil, nil
+	}
+	hash := cache.Hash(content)
+	node.Hash = hash
+	if store != nil {
+		if entry, ok := store.LookupContent(node.Path, info, hash, opts.Skeleton); ok {
+			return applyEntry(node, entry, opts.Skeleton), nil
+		}
+	}
+	if reason := classifyContent(node.Path, content); reason != "" {
+		node.Skipped = reason
+		if store != nil {
+			store.Store(node.Path, info, &cache.Entry{Hash: hash, HasSkeleton: opts.Skeleton, Skipped: reason})
+		}
+		return nil, nil
+	}
+
+	parseCtx := ctx
+	if opts.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		parseCtx, cancel = context.WithTimeout(ctx, opts.FileTimeout)
+		defer cancel()
+	}
+	result, err := parser.Parse(parseCtx, content, lang, parser.Options{Skeleton: opts.Skeleton})
+	if err != nil {
+		// The file is still counted, only without telling comments apart.
+		setLines(node, parser.CountLines(content))
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return nil, &fileError{phase: PhaseParse, kind: KindTimeout, err: fmt.Errorf("gave up after %s", opts.FileTimeout)}
+		}
+		return nil, &fileError{phase: PhaseParse, kind: KindParseFailed, err: err}
+	}
+	setLines(node, result.Lines)
+	node.CodeElements = result.Elements
+	node.Imports = result.Imports
+	node.Routes = result.Routes
+	node.Annotations = result.Annotations
+	node.Skeleton = result.Skeleton
+
+	if store != nil {
+		// Redaction rewrites elements, routes and annotations in place, so
+		// the cache keeps its own copy.
+		store.Store(node.Path, info, &cache.Entry{
+			Hash:         hash,
+			Lines:        result.Lines,
+			Elements:     append([]model.CodeElement(nil), result.Elements...),
+			Imports:      result.Imports,
+			Routes:       append([]model.Route(nil), result.Routes...),
+			Annotations:  append([]model.Annotation(nil), result.Annotations...),
+			Skeleton:     result.Skeleton,
+			HasSkeleton:  opts.Skeleton,
+			SyntaxErrors: result.SyntaxErrors,
+		})
+	}
+	return result.SyntaxErrors, nil
+}
+
+// applyEntry copies cached parse results into a node and returns the cached
+// syntax errors. The cached skeleton is only used when one was requested.
+func applyEntry(node *model.Node, entry *cache.Entry, skeleton bool) []model.SyntaxError {
+	setLines(node, entry.Lines)
+	node.CodeElements = append([]model.CodeElement(nil), entry.Elements...)
+	node.Imports = entry.Imports
+	node.Routes = append([]model.Route(nil), entry.Routes...)
+	node.Annotations = append([]model.Annotation(nil), entry.Annotations...)
+	if skeleton {
+		node.Skeleton = entry.Skeleton
+	}
+	node.Hash = entry.Hash
+	node.Skipped = entry.Skipped
+	return entry.SyntaxErrors
+}
+
+// setLines records the line counts of a file node.
+func setLines(node *model.Node, lines model.LineCounts) {
+	node.LOC = lines.Physical()
+	node.CodeLines = lines.Code
+	node.CommentLines = lines.Comment
+	node.BlankLines = lines.Blank
+}
+
+// tooLarge reports whether a file of this size exceeds opts.MaxFileSize.
+func tooLarge(size int64, opts Options) bool {
+	return opts.MaxFileSize > 0 && size > opts.MaxFileSize
+}
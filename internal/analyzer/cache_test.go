@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+func TestParseNodeCountsLinesWhenParsingFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\n// main does nothing.\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var lang model.Language
+	for _, l := range CompiledLanguageConfig.Languages {
+		if l.Name == "Go" {
+			lang = l
+		}
+	}
+
+	// The deadline passes before the parse starts.
+	node := &model.Node{Path: path}
+	_, err := parseNode(context.Background(), node, lang, Options{FileTimeout: time.Nanosecond}, nil, nil)
+	var fe *fileError
+	if !errors.As(err, &fe) || fe.phase != PhaseParse {
+		t.Fatalf("parseNode error = %v, want a parse error", err)
+	}
+	if node.LOC != 4 || node.CodeLines != 3 || node.BlankLines != 1 {
+		t.Errorf("LOC = %d (code %d, comment %d, blank %d), want 4 lines, 3 of them code and 1 blank",
+			node.LOC, node.CodeLines, node.CommentLines, node.BlankLines)
+	}
+}
+
+func TestCachedSkeletonsOnlyWhenRequested(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(1)\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := t.TempDir()
+	for _, opts := range []Options{
+		{Skeleton: true, CacheDir: cacheDir},
+		{CacheDir: cacheDir},
+		{Skeleton: true, CacheDir: cacheDir},
+	} {
+		result, err := Analyze(context.Background(), root, nil, nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, node := range collectFileNodes(result.Root) {
+			if hasSkeleton := node.Skeleton != ""; hasSkeleton != opts.Skeleton {
+				t.Errorf("Skeleton=%v: %s has skeleton %q", opts.Skeleton, filepath.Base(node.Path), node.Skeleton)
+			}
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/parser"
+)
+
+// Element change kinds reported in model.ElementChange.
+const (
+	ElementAdded            = "added"
+	ElementRemoved          = "removed"
+	ElementSignatureChanged = "signature_changed"
+)
+
+// changeBase describes the git revision a changed-since analysis compares against.
+type changeBase struct {
+	// root is the analyzed directory that git paths are relative to.
+	root string
+	// ref is the revision holding the old version of each file.
+	ref string
+	// staged reads the new version of each file from the index.
+	staged bool
+}
+
+// newChangeBase returns the comparison base for opts, or nil when the
+// analysis is not restricted to changed files.
+func newChangeBase(root string, opts Options) *changeBase {
+	if opts.Since == "" && !opts.Staged {
+		return nil
+	}
+	ref := opts.Since
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return &changeBase{root: root, ref: ref, staged: opts.Staged}
+}
+
+// label describes the base for the report header.
+func (b *changeBase) label() string {
+	if b.staged {
+		return fmt.Sprintf("%s (staged changes)", b.ref)
+	}
+	return b.ref
+}
+
+// relPath converts an absolute node path to the form git expects.
+func (b *changeBase) relPath(path string) string {
+	return filepath.ToSlash(relativePath(b.root, path))
+}
+
+// readCurrent returns the new version of a changed file.
+func (b *changeBase) readCurrent(ctx context.Context, node *model.Node) ([]byte, error) {
+	if b.staged {
+		return git.ShowFile(ctx, b.root, "", b.relPath(node.Path))
+	}
+	return os.ReadFile(node.Path)
+}
+
+// oldElements parses the version of a changed file at the base ref.
+func (b *changeBase) oldElements(ctx context.Context, node *model.Node, lang model.Language) ([]model.CodeElement, error) {
+	oldPath := node.Path
+	if node.OldPath != "" {
+		oldPath = node.OldPath
+		if oldLang, ok := GetLanguageByFileExtension(oldPath); ok {
+			lang = oldLang
+		}
+	}
+	content, err := git.ShowFile(ctx, b.root, b.ref, b.relPath(oldPath))
+	if err != nil {
+		return nil, err
+	}
+	result, err := parser.Parse(ctx, content, lang, parser.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Elements, nil
+}
+
+// compare records how the elements of a changed file differ from the base ref.
+func (b *changeBase) compare(ctx context.Context, node *model.Node, lang model.Language) error {
+	var oldElements []model.CodeElement
+	if node.Status != git.StatusAdded {
+		var err error
+		oldElements, err = b.oldElements(ctx, node, lang)
+		if err != nil {
+			return err
+		}
+	}
+	node.ElementChanges = diffElements(oldElements, node.CodeElements)
+	return nil
+}
+
+// elementKey identifies an element across two versions of a file.
+type elementKey struct {
+	Type string
+	Name string
+}
+
+// diffElements reports the elements added, removed, or whose signature
+// changed between two versions of a file. Elements sharing a type and name,
+// such as overloads, are paired up by signature first.
+func diffElements(oldElements, newElements []model.CodeElement) []model.ElementChange {
+	oldByKey := make(map[elementKey][]model.CodeElement)
+	for _, el := range oldElements {
+		key := elementKey{Type: el.Type, Name: el.Name}
+		oldByKey[key] = append(oldByKey[key], el)
+	}
+	newByKey := make(map[elementKey][]model.CodeElement)
+	var keys []elementKey
+	for _, el := range newElements {
+		key := elementKey{Type: el.Type, Name: el.Name}
+		if _, ok := newByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		newByKey[key] = append(newByKey[key], el)
+	}
+	for _, el := range oldElements {
+		key := elementKey{Type: el.Type, Name: el.Name}
+		if _, ok := newByKey[key]; !ok {
+			newByKey[key] = nil
+			keys = append(keys, key)
+		}
+	}
+
+	var changes []model.ElementChange
+	for _, key := range keys {
+		olds, news := unmatchedBySignature(oldByKey[key], newByKey[key])
+		for len(olds) > 0 && len(news) > 0 {
+			changes = append(changes, model.ElementChange{
+				Change:       ElementSignatureChanged,
+				Name:         key.Name,
+				Type:         key.Type,
+				Line:         news[0].Line,
+				Signature:    news[0].Signature,
+				OldSignature: olds[0].Signature,
+			})
+			olds, news = olds[1:], news[1:]
+		}
+		for _, el := range news {
+			changes = append(changes, model.ElementChange{Change: ElementAdded, Name: el.Name, Type: el.Type, Line: el.Line, Signature: el.Signature})
+		}
+		for _, el := range olds {
+			changes = append(changes, model.ElementChange{Change: ElementRemoved, Name: el.Name, Type: el.Type, Signature: el.Signature})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Line < changes[j].Line
+	})
+	return changes
+}
+
+// unmatchedBySignature drops the elements whose signature appears on both
+// sides and returns what is left of each.
+func unmatchedBySignature(olds, news []model.CodeElement) ([]model.CodeElement, []model.CodeElement) {
+	remaining := make(map[string]int)
+	for _, el := range olds {
+		remaining[el.Signature]++
+	}
+	var newLeft []model.CodeElement
+	for _, el := range news {
+		if remaining[el.Signature] > 0 {
+			remaining[el.Signature]--
+			continue
+		}
+		newLeft = append(newLeft, el)
+	}
+	var oldLeft []model.CodeElement
+	for _, el := range olds {
+		if remaining[el.Signature] > 0 {
+			remaining[el.Signature]--
+			oldLeft = append(oldLeft, el)
+		}
+	}
+	return oldLeft, newLeft
+}
+
+// summarizeChanges totals the change statuses of the analyzed files.
+func summarizeChanges(base *changeBase, fileNodes []*model.Node) *model.ChangeSummary {
+	summary := &model.ChangeSummary{Base: base.ref, Staged: base.staged}
+	for _, node := range fileNodes {
+		addChange(summary, node)
+	}
+	return summary
+}
+
+// addChange adds the change status of a single file to the summary.
+func addChange(summary *model.ChangeSummary, node *model.Node) {
+	switch node.Status {
+	case git.StatusAdded:
+		summary.FilesAdded++
+	case git.StatusModified:
+		summary.FilesModified++
+	case git.StatusDeleted:
+		summary.FilesDeleted++
+	case git.StatusRenamed:
+		summary.FilesRenamed++
+	}
+	for _, change := range node.ElementChanges {
+		switch change.Change {
+		case ElementAdded:
+			summary.ElementsAdded++
+		case ElementRemoved:
+			summary.ElementsRemoved++
+		case ElementSignatureChanged:
+			summary.SignaturesChanged++
+		}
+	}
+}
+
+// formatElementChange renders one element change as a tree line suffix.
+func formatElementChange(change model.ElementChange) string {
+	switch change.Change {
+	case ElementAdded:
+		return fmt.Sprintf("+ %s: %s (L%d)", change.Type, change.Name, change.Line)
+	case ElementRemoved:
+		return fmt.Sprintf("- %s: %s", change.Type, change.Name)
+	default:
+		return fmt.Sprintf("~ %s: %s (L%d) signature: %s → %s", change.Type, change.Name, change.Line, change.OldSignature, change.Signature)
+	}
+}
+
+// statusLabel returns the marker appended to a changed file in the tree.
+func statusLabel(node *model.Node) string {
+	if node.Status == "" {
+		return ""
+	}
+	if node.Status == git.StatusRenamed && node.OldPath != "" {
+		from := relativePath(filepath.Dir(node.Path), node.OldPath)
+		return fmt.Sprintf(" [renamed from %s]", from)
+	}
+	return fmt.Sprintf(" [%s]", node.Status)
+}
+
+// appendChanges writes the change summary section of the report.
+func appendChanges(builder *strings.Builder, summary *model.ChangeSummary) {
+	base := &changeBase{ref: summary.Base, staged: summary.Staged}
+	builder.WriteString(fmt.Sprintf("🔀 Changes since %s\n", base.label()))
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%-20s %d added, %d modified, %d deleted, %d renamed\n", "Files:",
+		summary.FilesAdded, summary.FilesModified, summary.FilesDeleted, summary.FilesRenamed))
+	builder.WriteString(fmt.Sprintf("%-20s %d added, %d removed, %d signature changes\n", "Elements:",
+		summary.ElementsAdded, summary.ElementsRemoved, summary.SignaturesChanged))
+	builder.WriteString("\n")
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+func element(typ, name, signature string, line int) model.CodeElement {
+	return model.CodeElement{Type: typ, Name: name, Signature: signature, Line: line}
+}
+
+func TestDiffElements(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []model.CodeElement
+		new  []model.CodeElement
+		want []model.ElementChange
+	}{
+		{
+			name: "unchanged",
+			old:  []model.CodeElement{element("Function", "Run", "func Run()", 3)},
+			new:  []model.CodeElement{element("Function", "Run", "func Run()", 5)},
+		},
+		{
+			name: "added and removed",
+			old:  []model.CodeElement{element("Function", "Old", "func Old()", 3)},
+			new:  []model.CodeElement{element("Function", "New", "func New()", 7)},
+			want: []model.ElementChange{
+				{Change: ElementRemoved, Type: "Function", Name: "Old", Signature: "func Old()"},
+				{Change: ElementAdded, Type: "Function", Name: "New", Line: 7, Signature: "func New()"},
+			},
+		},
+		{
+			name: "signature changed",
+			old:  []model.CodeElement{element("Function", "Run", "func Run()", 3)},
+			new:  []model.CodeElement{element("Function", "Run", "func Run(ctx context.Context)", 3)},
+			want: []model.ElementChange{
+				{Change: ElementSignatureChanged, Type: "Function", Name: "Run", Line: 3, Signature: "func Run(ctx context.Context)", OldSignature: "func Run()"},
+			},
+		},
+		{
+			name: "same name, different type",
+			old:  []model.CodeElement{element("Struct", "Config", "type Config struct", 2)},
+			new:  []model.CodeElement{element("Interface", "Config", "type Config interface", 2)},
+			want: []model.ElementChange{
+				{Change: ElementRemoved, Type: "Struct", Name: "Config", Signature: "type Config struct"},
+				{Change: ElementAdded, Type: "Interface", Name: "Config", Line: 2, Signature: "type Config interface"},
+			},
+		},
+		{
+			// An overload that kept its signature is not reported, even
+			// when it moved ahead of the one that changed.
+			name: "overloads paired by signature",
+			old: []model.CodeElement{
+				element("Method", "add", "void add(int a)", 4),
+				element("Method", "add", "void add(String s)", 8),
+			},
+			new: []model.CodeElement{
+				element("Method", "add", "void add(String s)", 4),
+				element("Method", "add", "void add(long a)", 8),
+			},
+			want: []model.ElementChange{
+				{Change: ElementSignatureChanged, Type: "Method", Name: "add", Line: 8, Signature: "void add(long a)", OldSignature: "void add(int a)"},
+			},
+		},
+		{
+			name: "new overload",
+			old:  []model.CodeElement{element("Method", "add", "void add(int a)", 4)},
+			new: []model.CodeElement{
+				element("Method", "add", "void add(int a)", 4),
+				element("Method", "add", "void add(int a, int b)", 9),
+			},
+			want: []model.ElementChange{
+				{Change: ElementAdded, Type: "Method", Name: "add", Line: 9, Signature: "void add(int a, int b)"},
+			},
+		},
+		{
+			name: "added file",
+			new:  []model.CodeElement{element("Function", "Run", "func Run()", 3)},
+			want: []model.ElementChange{
+				{Change: ElementAdded, Type: "Function", Name: "Run", Line: 3, Signature: "func Run()"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffElements(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffElements =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmatchedBySignature(t *testing.T) {
+	olds := []model.CodeElement{
+		element("Method", "f", "f(a)", 1),
+		element("Method", "f", "f(a)", 2),
+		element("Method", "f", "f(b)", 3),
+	}
+	news := []model.CodeElement{
+		element("Method", "f", "f(a)", 1),
+		element("Method", "f", "f(c)", 2),
+	}
+	oldLeft, newLeft := unmatchedBySignature(olds, news)
+	// Duplicated signatures are matched one for one; the first copies are
+	// the ones left over.
+	if want := []model.CodeElement{olds[0], olds[2]}; !reflect.DeepEqual(oldLeft, want) {
+		t.Errorf("old left = %+v, want %+v", oldLeft, want)
+	}
+	if want := []model.CodeElement{news[1]}; !reflect.DeepEqual(newLeft, want) {
+		t.Errorf("new left = %+v, want %+v", newLeft, want)
+	}
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// Reasons a file is listed without being analyzed, set in model.Node.Skipped.
+const (
+	SkipBinary    = "binary"
+	SkipGenerated = "generated"
+	SkipMinified  = "minified"
+	SkipTooLarge  = "too large"
+)
+
+// DefaultMaxFileSize is the size above which files are skipped unless
+// configured otherwise.
+const DefaultMaxFileSize = 1 << 20
+
+// sniffLen is how much of a file is inspected to tell binary from text, the
+// same amount git looks at.
+const sniffLen = 8000
+
+// headerLines is how many leading lines may carry a generated-code marker.
+const headerLines = 20
+
+var (
+	// generatedMarker matches the Go convention (golang.org/s/generatedcode)
+	// and the @generated tag used by protoc, Thrift, Relay and others.
+	generatedMarker = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$|@generated\b`)
+	// minifiedExts are the languages that are commonly shipped minified.
+	minifiedExts = map[string]bool{".js": true, ".mjs": true, ".cjs": true, ".css": true}
+)
+
+// classifyContent returns why a file with this content should not be
+// analyzed, or "" when it is ordinary source code.
+func classifyContent(path string, content []byte) string {
+	switch {
+	case isBinary(content):
+		return SkipBinary
+	case generatedMarker.Match(header(content)):
+		return SkipGenerated
+	case isMinified(path, content):
+		return SkipMinified
+	}
+	return ""
+}
+
+// isBinary reports whether content looks like binary data: it contains a NUL
+// byte or is not valid UTF-8 near the start.
+func isBinary(content []byte) bool {
+	if len(content) > sniffLen {
+		content = content[:sniffLen]
+		// Do not mistake a multi-byte character cut at the limit for garbage.
+		for i := 0; i < utf8.UTFMax && len(content) > 0 && !utf8.Valid(content); i++ {
+			content = content[:len(content)-1]
+		}
+	}
+	return bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content)
+}
+
+// header returns the first headerLines lines of content.
+func header(content []byte) []byte {
+	end := 0
+	for i := 0; i < headerLines && end < len(content); i++ {
+		next := bytes.IndexByte(content[end:], '\n')
+		if next < 0 {
+			return content
+		}
+		end += next + 1
+	}
+	return content[:end]
+}
+
+// isMinified reports whether a JavaScript or CSS file is minified: named
+// *.min.* or made of a few very long lines.
+func isMinified(path string, content []byte) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !minifiedExts[ext] {
+		return false
+	}
+	if strings.HasSuffix(strings.ToLower(filepath.Base(path)), ".min"+ext) {
+		return true
+	}
+	if len(content) < 1024 {
+		return false
+	}
+	lines := bytes.Count(content, []byte("\n")) + 1
+	return len(content)/lines > 500
+}
+
+// sniffFile classifies a file groot has no parser for, reading only its
+// first bytes. It returns "" when the file could not be read.
+func sniffFile(node *model.Node, opts Options) string {
+	f, err := os.Open(node.Path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+		return SkipTooLarge
+	}
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return ""
+	}
+	if isBinary(buf[:n]) {
+		return SkipBinary
+	}
+	return ""
+}
+
+// pruneSkipped removes skipped files from a tree, along with the directories
+// that held nothing else.
+func pruneSkipped(node *model.Node) {
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if !child.IsDir && child.Skipped != "" {
+			continue
+		}
+		if child.IsDir && len(child.Children) > 0 {
+			pruneSkipped(child)
+			if len(child.Children) == 0 {
+				continue
+			}
+		}
+		children = append(children, child)
+	}
+	node.Children = children
+}
+
+// skippedLabel marks a file that is listed but was not analyzed.
+func skippedLabel(node *model.Node) string {
+	if node.Skipped == "" {
+		return ""
+	}
+	return " (skipped: " + node.Skipped + ")"
+}
@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// Defaults of the complexity report.
+const (
+	DefaultComplexityTop       = 10
+	DefaultComplexityThreshold = 15
+)
+
+// markHotspots flags the elements of node whose cognitive complexity reaches
+// threshold. A zero threshold flags nothing.
+func markHotspots(node *model.Node, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	for i := range node.CodeElements {
+		node.CodeElements[i].Hotspot = node.CodeElements[i].Cognitive >= threshold
+	}
+}
+
+// complexityRanking keeps the most complex functions seen so far.
+type complexityRanking struct {
+	top       int
+	threshold int
+	hotspots  int
+	functions []model.ComplexFunction
+}
+
+// newComplexityRanking returns the ranking configured by opts, or nil when
+// the report is disabled.
+func newComplexityRanking(opts Options) *complexityRanking {
+	if opts.ComplexityTop <= 0 {
+		return nil
+	}
+	return &complexityRanking{top: opts.ComplexityTop, threshold: opts.ComplexityThreshold}
+}
+
+// add ranks the functions of a file node.
+func (r *complexityRanking) add(node *model.Node) {
+	if r == nil {
+		return
+	}
+	for _, el := range node.CodeElements {
+		if el.Cyclomatic == 0 {
+			continue
+		}
+		if el.Hotspot {
+			r.hotspots++
+		}
+		r.functions = append(r.functions, model.ComplexFunction{
+			Path:       node.Path,
+			Name:       el.Name,
+			Type:       el.Type,
+			Line:       el.Line,
+			Cyclomatic: el.Cyclomatic,
+			Cognitive:  el.Cognitive,
+		})
+	}
+	// Trim now and then so a streamed analysis stays bounded.
+	if len(r.functions) > 4*r.top {
+		r.trim()
+	}
+}
+
+// trim sorts the functions and drops all but the top ones.
+func (r *complexityRanking) trim() {
+	sort.SliceStable(r.functions, func(i, j int) bool {
+		a, b := r.functions[i], r.functions[j]
+		if a.Cognitive != b.Cognitive {
+			return a.Cognitive > b.Cognitive
+		}
+		if a.Cyclomatic != b.Cyclomatic {
+			return a.Cyclomatic > b.Cyclomatic
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	if len(r.functions) > r.top {
+		r.functions = r.functions[:r.top]
+	}
+}
+
+// report returns the final ranking, or nil when it is disabled or no
+// function was found.
+func (r *complexityRanking) report() *model.ComplexityReport {
+	if r == nil || len(r.functions) == 0 {
+		return nil
+	}
+	r.trim()
+	return &model.ComplexityReport{Threshold: r.threshold, Hotspots: r.hotspots, Top: r.functions}
+}
+
+// formatElement renders an element line of the tree, flagging hotspots.
+func formatElement(el model.CodeElement) string {
+	line := fmt.Sprintf("- %s: %s (L%d)", el.Type, el.Name, el.Line)
+	if el.Hotspot {
+		line += fmt.Sprintf(" 🔥 cognitive %d, cyclomatic %d", el.Cognitive, el.Cyclomatic)
+	}
+	return line
+}
+
+// appendComplexity lists the most complex functions.
+func appendComplexity(builder *strings.Builder, rootPath string, report *model.ComplexityReport) {
+	builder.WriteString("🔥 Most Complex Functions\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	if report.Threshold > 0 {
+		builder.WriteString(fmt.Sprintf("%d function(s) at or above cognitive complexity %d.\n", report.Hotspots, report.Threshold))
+	}
+	builder.WriteString(fmt.Sprintf("  %9s %10s  %s\n", "cognitive", "cyclomatic", "function"))
+	for _, fn := range report.Top {
+		relPath := relativePath(rootPath, fn.Path)
+		builder.WriteString(fmt.Sprintf("  %9d %10d  %s:%d %s\n", fn.Cognitive, fn.Cyclomatic, relPath, fn.Line, fn.Name))
+	}
+	builder.WriteString("\n")
+}
@@ -14,6 +14,24 @@ var CompiledLanguageConfig = model.LanguageConfig{
 				{Type: "Method", Query: `(method_declaration name: (field_identifier) @name)`},
 				{Type: "Interface", Query: `(type_spec name: (type_identifier) @name (interface_type))`},
 				{Type: "Struct", Query: `(type_spec name: (type_identifier) @name (struct_type))`},
+				{Type: "Entry Point", Query: `((source_file (package_clause (package_identifier) @pkg) (function_declaration name: (identifier) @name)) (#eq? @pkg "main") (#eq? @name "main"))`},
+				{Type: "Command", Query: `((composite_literal type: (qualified_type package: (package_identifier) @pkg name: (type_identifier) @type) body: (literal_value (keyed_element (literal_element (identifier) @key) (literal_element (interpreted_string_literal (interpreted_string_literal_content) @name))))) (#eq? @pkg "cobra") (#eq? @type "Command") (#eq? @key "Use"))`},
+			},
+			ImportQueries: []string{
+				`(import_spec path: (interpreted_string_literal) @path)`,
+			},
+			RouteQueries: []string{
+				// net/http, gorilla/mux and Go 1.22 method patterns.
+				`((call_expression function: (selector_expression operand: (_) @receiver field: (field_identifier) @method) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @path) . (_) @handler)) @route (#match? @method "^(HandleFunc|Handle)$") (#match? @path "^(/|[A-Z]+ /)"))`,
+				// gin and echo, on a group made in place: r.Group("/api").GET("/ping", ping).
+				`((call_expression function: (selector_expression operand: (call_expression function: (selector_expression operand: (_) @receiver field: (field_identifier) @fn) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @prefix))) field: (field_identifier) @method) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @path) (_) @handler .)) @route (#eq? @fn "Group") (#match? @method "^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|Any)$") (#match? @path "^/"))`,
+				// gin, echo and chi.
+				`((call_expression function: (selector_expression operand: (_) @receiver field: (field_identifier) @method) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @path) (_) @handler .)) @route (#match? @method "^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|Any|Get|Post|Put|Delete|Patch|Head|Options)$") (#match? @path "^/"))`,
+				// gin and echo groups: v1 := r.Group("/v1").
+				`((short_var_declaration left: (expression_list . (identifier) @group) right: (expression_list . (call_expression function: (selector_expression operand: (_) @receiver field: (field_identifier) @fn) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @prefix))))) (#eq? @fn "Group"))`,
+				`((assignment_statement left: (expression_list . (identifier) @group) right: (expression_list . (call_expression function: (selector_expression operand: (_) @receiver field: (field_identifier) @fn) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @prefix))))) (#eq? @fn "Group"))`,
+				// chi subrouters built in place: r.Route("/v1", func(r chi.Router) { ... }).
+				`((call_expression function: (selector_expression field: (field_identifier) @fn) arguments: (argument_list . (interpreted_string_literal (interpreted_string_literal_content) @prefix) . (_) @scope)) (#match? @fn "^(Route|Mount)$"))`,
 			},
 		},
 		{
@@ -29,6 +47,21 @@ var CompiledLanguageConfig = model.LanguageConfig{
 				{Type: "Class", Query: `(class_declaration name: (identifier) @name)`},
 				{Type: "Class Component", Query: `(export_statement declaration: (class_declaration name: (identifier) @name))`},
 				{Type: "Method", Query: `(method_definition name: (property_identifier) @name)`},
+				{Type: "Test Suite", Query: `((call_expression function: (identifier) @fn arguments: (arguments . (string (string_fragment) @name))) (#eq? @fn "describe"))`},
+				{Type: "Test", Query: `((call_expression function: (identifier) @fn arguments: (arguments . (string (string_fragment) @name))) (#match? @fn "^(it|test)$"))`},
+				{Type: "Express App", Query: `((variable_declarator name: (identifier) @name value: (call_expression function: (identifier) @fn)) (#eq? @fn "express"))`},
+			},
+			ImportQueries: []string{
+				`(import_statement source: (string) @path)`,
+				`(export_statement source: (string) @path)`,
+				`((call_expression function: (identifier) @fn arguments: (arguments (string) @path)) (#eq? @fn "require"))`,
+			},
+			RouteQueries: []string{
+				// Express: app.get("/path", ...middleware, handler), where the
+				// handler is a function or a name rather than a value.
+				`((call_expression function: (member_expression object: (_) @receiver property: (property_identifier) @method) arguments: (arguments . (string (string_fragment) @path) [(identifier) (member_expression) (call_expression) (arrow_function) (function_expression)] @handler .)) @route (#match? @method "^(get|post|put|delete|patch|head|options|all)$") (#match? @path "^/"))`,
+				// Express applications and routers: const app = express().
+				`((variable_declarator name: (identifier) @router value: (call_expression function: (_) @ctor)) (#match? @ctor "^(express|express.Router|Router)$"))`,
 			},
 		},
 		{
@@ -41,6 +74,30 @@ var CompiledLanguageConfig = model.LanguageConfig{
 				{Type: "Class", Query: `(class_declaration name: (identifier) @name)`},
 				{Type: "Method", Query: `(method_declaration name: (identifier) @name)`},
 				{Type: "Interface", Query: `(interface_declaration name: (identifier) @name)`},
+				{Type: "Test", Query: `((method_declaration (modifiers [(marker_annotation name: (identifier) @ann) (annotation name: (identifier) @ann)]) name: (identifier) @name) (#match? @ann "^(Test|ParameterizedTest|RepeatedTest)$"))`},
+				{Type: "Spring Boot Application", Query: `((class_declaration (modifiers (marker_annotation name: (identifier) @ann)) name: (identifier) @name) (#eq? @ann "SpringBootApplication"))`},
+				{Type: "Entry Point", Query: `((method_declaration (modifiers "static") name: (identifier) @name) (#eq? @name "main"))`},
+			},
+			ImportQueries: []string{
+				`(import_declaration (scoped_identifier) @path)`,
+			},
+			RouteQueries: []string{
+				// Spring: @RequestMapping(value = "/path", method = RequestMethod.POST).
+				`((method_declaration (modifiers (annotation name: (identifier) @ann arguments: (annotation_argument_list (element_value_pair key: (identifier) @key value: (string_literal (string_fragment) @path)) (element_value_pair key: (identifier) @mkey value: (field_access field: (identifier) @methods))))) name: (identifier) @handler) @route (#eq? @ann "RequestMapping") (#match? @key "^(value|path)$") (#eq? @mkey "method"))`,
+				// Spring: @GetMapping(path = "/path").
+				`((method_declaration (modifiers (annotation name: (identifier) @method arguments: (annotation_argument_list (element_value_pair key: (identifier) @key value: (string_literal (string_fragment) @path))))) name: (identifier) @handler) @route (#match? @method "^(Get|Post|Put|Delete|Patch|Request)Mapping$") (#match? @key "^(value|path)$"))`,
+				// Spring: @GetMapping("/path").
+				`((method_declaration (modifiers (annotation name: (identifier) @method arguments: (annotation_argument_list . (string_literal (string_fragment) @path)))) name: (identifier) @handler) @route (#match? @method "^(Get|Post|Put|Delete|Patch|Request)Mapping$"))`,
+				// Spring: @GetMapping, on the controller's path.
+				`((method_declaration (modifiers (marker_annotation name: (identifier) @method)) name: (identifier) @handler) @route (#match? @method "^(Get|Post|Put|Delete|Patch|Request)Mapping$"))`,
+				// JAX-RS: @GET with @Path, in either order.
+				`((method_declaration (modifiers (marker_annotation name: (identifier) @method) (annotation name: (identifier) @ann arguments: (annotation_argument_list (string_literal (string_fragment) @path)))) name: (identifier) @handler) @route (#match? @method "^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)$") (#eq? @ann "Path"))`,
+				`((method_declaration (modifiers (annotation name: (identifier) @ann arguments: (annotation_argument_list (string_literal (string_fragment) @path))) (marker_annotation name: (identifier) @method)) name: (identifier) @handler) @route (#match? @method "^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)$") (#eq? @ann "Path"))`,
+				// JAX-RS: @GET alone, on the resource's path.
+				`((method_declaration (modifiers (marker_annotation name: (identifier) @method)) name: (identifier) @handler) @route (#match? @method "^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)$"))`,
+				// Class-level paths of Spring controllers and JAX-RS resources.
+				`((class_declaration (modifiers (annotation name: (identifier) @ann arguments: (annotation_argument_list . (string_literal (string_fragment) @prefix)))) body: (class_body) @scope) (#match? @ann "^(RequestMapping|Path)$"))`,
+				`((class_declaration (modifiers (annotation name: (identifier) @ann arguments: (annotation_argument_list (element_value_pair key: (identifier) @key value: (string_literal (string_fragment) @prefix))))) body: (class_body) @scope) (#eq? @ann "RequestMapping") (#match? @key "^(value|path)$"))`,
 			},
 		},
 		{
@@ -49,6 +106,20 @@ var CompiledLanguageConfig = model.LanguageConfig{
 			Queries: []model.LanguageQuery{
 				{Type: "Function", Query: `(function_definition name: (identifier) @name)`},
 				{Type: "Class", Query: `(class_definition name: (identifier) @name)`},
+				{Type: "Entry Point", Query: `((if_statement condition: (comparison_operator (identifier) @id (string (string_content) @name))) (#eq? @id "__name__") (#eq? @name "__main__"))`},
+				{Type: "FastAPI App", Query: `((assignment left: (identifier) @name right: (call function: (identifier) @fn)) (#eq? @fn "FastAPI"))`},
+				{Type: "Flask App", Query: `((assignment left: (identifier) @name right: (call function: (identifier) @fn)) (#eq? @fn "Flask"))`},
+			},
+			ImportQueries: []string{
+				`(import_statement name: (dotted_name) @path)`,
+				`(import_statement name: (aliased_import name: (dotted_name) @path))`,
+				`(import_from_statement module_name: (_) @path)`,
+			},
+			RouteQueries: []string{
+				// Flask and FastAPI: @app.route("/path", methods=["POST"]).
+				`((decorated_definition (decorator (call function: (attribute attribute: (identifier) @method) arguments: (argument_list . (string (string_content) @path) (keyword_argument name: (identifier) @kw value: (list (string (string_content) @methods)))))) definition: (function_definition name: (identifier) @handler)) @route (#match? @method "^(route|api_route)$") (#eq? @kw "methods"))`,
+				// FastAPI and Flask: @app.get("/path"), @router.post("/path").
+				`((decorated_definition (decorator (call function: (attribute attribute: (identifier) @method) arguments: (argument_list . (string (string_content) @path)))) definition: (function_definition name: (identifier) @handler)) @route (#match? @method "^(get|post|put|delete|patch|head|options|route|api_route|websocket)$"))`,
 			},
 		},
 		{
@@ -59,6 +130,12 @@ var CompiledLanguageConfig = model.LanguageConfig{
 				{Type: "Struct", Query: `(struct_item name: (type_identifier) @name)`},
 				{Type: "Enum", Query: `(enum_item name: (type_identifier) @name)`},
 				{Type: "Trait", Query: `(trait_item name: (type_identifier) @name)`},
+				{Type: "Test", Query: `((attribute_item (attribute [(identifier) @attr (scoped_identifier) @attr])) . [(attribute_item) (line_comment) (block_comment)]* . (function_item name: (identifier) @name) (#match? @attr "^(([a-z_]+::)?test|rstest|test_case)$"))`},
+				{Type: "Entry Point", Query: `((function_item name: (identifier) @name) (#eq? @name "main"))`},
+			},
+			ImportQueries: []string{
+				`(use_declaration argument: (_) @path)`,
+				`(extern_crate_declaration name: (identifier) @path)`,
 			},
 		},
 		{
@@ -0,0 +1,216 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/parser"
+)
+
+// languageNamed returns the configured language called name.
+func languageNamed(t *testing.T, name string) model.Language {
+	t.Helper()
+	for _, lang := range CompiledLanguageConfig.Languages {
+		if lang.Name == name {
+			return lang
+		}
+	}
+	t.Fatalf("no language %s", name)
+	return model.Language{}
+}
+
+// elementsOfType parses source as the named language and returns the names
+// of its elements of type typ, in order.
+func elementsOfType(t *testing.T, language, source, typ string) []string {
+	t.Helper()
+	result, err := parser.Parse(context.Background(), []byte(source), languageNamed(t, language), parser.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, el := range result.Elements {
+		if el.Type == typ {
+			names = append(names, el.Name)
+		}
+	}
+	return names
+}
+
+func TestTestQueries(t *testing.T) {
+	tests := []struct {
+		language string
+		source   string
+		want     []string
+	}{
+		{"Java", `class FooTest {
+    @Test
+    void plain() {}
+
+    @Test(timeout = 5)
+    void withArguments() {}
+
+    @ParameterizedTest
+    @ValueSource(ints = {1, 2})
+    void parameterized(int n) {}
+
+    @Override
+    public String toString() { return ""; }
+}
+`, []string{"plain", "withArguments", "parameterized"}},
+		{"Rust", `#[test]
+fn plain() {}
+
+#[tokio::test]
+async fn on_tokio() {}
+
+#[rstest]
+#[case(1)]
+fn with_cases(#[case] n: u32) {}
+
+#[test]
+// Comments between the attribute and the function are fine.
+#[should_panic]
+fn panics() {}
+
+#[inline]
+fn helper() {}
+`, []string{"plain", "on_tokio", "with_cases", "panics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if got := elementsOfType(t, tt.language, tt.source, "Test"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tests = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryPointQueries(t *testing.T) {
+	tests := []struct {
+		language string
+		source   string
+		want     []string
+	}{
+		{"Python", `if __name__ == "__main__":
+    main()
+
+if __name__ == "worker":
+    pass
+`, []string{"__main__"}},
+		{"Go", "package main\n\nfunc main() {}\n", []string{"main"}},
+		{"Go", "package lib\n\nfunc main() {}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if got := elementsOfType(t, tt.language, tt.source, ElementEntryPoint); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entry points = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// routesOf parses source as the named language and returns its routes as
+// "METHOD path handler".
+func routesOf(t *testing.T, language, source string) []string {
+	t.Helper()
+	result, err := parser.Parse(context.Background(), []byte(source), languageNamed(t, language), parser.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routes []string
+	for _, r := range result.Routes {
+		routes = append(routes, r.Method+" "+r.Path+" "+r.Handler)
+	}
+	return routes
+}
+
+func TestRouteQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		source   string
+		want     []string
+	}{
+		{"net/http", "Go", `package main
+
+func main() {
+	http.HandleFunc("GET /items/{id}", getItem)
+	mux.Handle("/static/", files)
+}
+`, []string{"GET /items/{id} getItem", "ANY /static/ files"}},
+		{"gin groups", "Go", `package main
+
+func routes(r *gin.Engine) {
+	r.GET("/health", health)
+	v1 := r.Group("/v1")
+	{
+		v1.POST("/users", createUser)
+		admin := v1.Group("/admin")
+		admin.DELETE("/users/:id", deleteUser)
+	}
+	r.Group("/api").GET("/ping", ping)
+	v1.Group("/beta").PUT("/flags", setFlags)
+}
+`, []string{
+			"GET /health health",
+			"POST /v1/users createUser",
+			"DELETE /v1/admin/users/:id deleteUser",
+			"GET /api/ping ping",
+			"PUT /v1/beta/flags setFlags",
+		}},
+		{"chi subrouters", "Go", `package main
+
+func routes(r chi.Router) {
+	r.Get("/", index)
+	r.Route("/v1", func(r chi.Router) {
+		r.Post("/orders", createOrder)
+		r.Route("/orders/{id}", func(r chi.Router) {
+			r.Get("/", getOrder)
+		})
+	})
+	r.Mount("/admin", func() http.Handler {
+		r := chi.NewRouter()
+		r.Get("/stats", stats)
+		return r
+	}())
+}
+`, []string{
+			"GET / index",
+			"POST /v1/orders createOrder",
+			"GET /v1/orders/{id}/ getOrder",
+			"GET /admin/stats stats",
+		}},
+		{"Spring controller prefix", "Java", `@RestController
+@RequestMapping("/api")
+class UserController {
+    @GetMapping("/users")
+    List<User> list() { return users; }
+
+    @PostMapping
+    User create() { return null; }
+}
+`, []string{"GET /api/users list", "POST /api create"}},
+		{"Express routers", "JavaScript", `const app = express();
+const router = express.Router();
+
+app.get("/health", (req, res) => res.send("ok"));
+router.post("/users", auth, createUser);
+axios.get("/api/users", handler);
+`, []string{"GET /health (inline)", "POST /users createUser"}},
+		{"Express without bindings", "JavaScript", `module.exports = (app) => {
+  app.delete("/users/:id", asyncHandler(deleteUser));
+  axios.get("/api/users", { headers });
+  cache.delete("/tmp/x", 1);
+};
+`, []string{"DELETE /users/:id asyncHandler(deleteUser)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routesOf(t, tt.language, tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("routes =\n  %q\nwant\n  %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/manifest"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// manifestSet collects the dependency manifests of an analysis.
+type manifestSet struct {
+	findings  *collector
+	redact    bool
+	manifests []model.Manifest
+}
+
+// newManifestSet returns an empty set that reports unreadable manifests and
+// redacted secrets to findings. redact replaces secrets in dependency
+// versions, such as tokens in git URLs.
+func newManifestSet(findings *collector, redact bool) *manifestSet {
+	return &manifestSet{findings: findings, redact: redact}
+}
+
+// add parses a file node when it is a dependency manifest.
+func (s *manifestSet) add(node *model.Node) {
+	name := filepath.Base(node.Path)
+	if !manifest.IsManifest(name) || node.Status == git.StatusDeleted {
+		return
+	}
+	content, err := os.ReadFile(node.Path)
+	if err != nil {
+		s.findings.addDiagnostics(model.Diagnostic{Path: node.Path, Phase: PhaseManifest, Kind: KindIO, Message: err.Error()})
+		return
+	}
+	m, err := manifest.Parse(name, content)
+	if err != nil {
+		s.findings.addDiagnostics(model.Diagnostic{Path: node.Path, Phase: PhaseManifest, Kind: KindParseFailed, Message: err.Error()})
+		return
+	}
+	m.Path = node.Path
+	if s.redact {
+		s.findings.addRedactions(redactManifest(m, content))
+	}
+	s.manifests = append(s.manifests, *m)
+}
+
+// list returns the manifests in path order.
+func (s *manifestSet) list() []model.Manifest {
+	sort.Slice(s.manifests, func(i, j int) bool { return s.manifests[i].Path < s.manifests[j].Path })
+	return s.manifests
+}
+
+// appendDependencies lists the direct dependencies of every manifest.
+func appendDependencies(builder *strings.Builder, rootPath string, manifests []model.Manifest) {
+	builder.WriteString("📦 Dependencies\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	for _, m := range manifests {
+		// Workspace files such as go.work only declare members.
+		if m.Module == "" && len(m.Dependencies) == 0 {
+			continue
+		}
+		relPath := relativePath(rootPath, m.Path)
+		header := fmt.Sprintf("▶ %s (%s", relPath, m.Ecosystem)
+		if m.Module != "" {
+			header += ", " + m.Module
+			if m.Version != "" {
+				header += " " + m.Version
+			}
+		}
+		builder.WriteString(fmt.Sprintf("%s, %d direct)\n", header, len(m.Dependencies)))
+		for _, dep := range m.Dependencies {
+			line := "  - " + dep.Name
+			if dep.Version != "" {
+				line += " " + dep.Version
+			}
+			if dep.Scope != "" {
+				line += fmt.Sprintf(" [%s]", dep.Scope)
+			}
+			builder.WriteString(line + "\n")
+		}
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/parser"
+)
+
+// Phases of the analysis reported in model.Diagnostic.
+const (
+	PhaseRead       = "read"
+	PhaseParse      = "parse"
+	PhaseCompare    = "compare"
+	PhaseCache      = "cache"
+	PhaseManifest   = "manifest"
+	PhaseBlame      = "blame"
+	PhaseHistory    = "history"
+	PhaseDuplicates = "duplicates"
+)
+
+// Kinds of problems reported in model.Diagnostic.
+const (
+	KindIO          = "io_error"
+	KindTimeout     = "timeout"
+	KindParseFailed = "parse_failed"
+	KindSyntax      = "syntax_error"
+	KindGit         = "git_error"
+	KindLimit       = "limit_reached"
+)
+
+// fileError is a failure to process a file, classified for the diagnostics list.
+type fileError struct {
+	phase string
+	kind  string
+	err   error
+}
+
+func (e *fileError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// diagnosticFor turns an error met while processing the file at path into a
+// diagnostic.
+func diagnosticFor(path string, err error) model.Diagnostic {
+	var fe *fileError
+	if errors.As(err, &fe) {
+		return model.Diagnostic{Path: path, Phase: fe.phase, Kind: fe.kind, Message: fe.err.Error()}
+	}
+	return model.Diagnostic{Path: path, Phase: PhaseParse, Kind: KindParseFailed, Message: err.Error()}
+}
+
+// syntaxDiagnostic summarizes the syntax errors of one file.
+func syntaxDiagnostic(path string, syntaxErrors []model.SyntaxError) model.Diagnostic {
+	first := syntaxErrors[0]
+	what := "unexpected input"
+	if first.Missing {
+		what = fmt.Sprintf("missing %q", first.Token)
+	}
+	count := fmt.Sprintf("%d syntax errors", len(syntaxErrors))
+	switch {
+	case len(syntaxErrors) == 1:
+		count = "1 syntax error"
+	case len(syntaxErrors) >= parser.MaxSyntaxErrors:
+		count = fmt.Sprintf("%d+ syntax errors", len(syntaxErrors))
+	}
+	return model.Diagnostic{
+		Path:    path,
+		Phase:   PhaseParse,
+		Kind:    KindSyntax,
+		Message: fmt.Sprintf("%s, first at L%d:%d (%s); elements may be missing", count, first.Line, first.Column, what),
+		Line:    first.Line,
+	}
+}
+
+// addDiagnostics records problems met in a single file.
+func (c *collector) addDiagnostics(diagnostics ...model.Diagnostic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diagnostics = append(c.diagnostics, diagnostics...)
+}
+
+// sortDiagnostics orders diagnostics by file, then phase.
+func sortDiagnostics(diagnostics []model.Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Path != diagnostics[j].Path {
+			return diagnostics[i].Path < diagnostics[j].Path
+		}
+		return diagnostics[i].Phase < diagnostics[j].Phase
+	})
+}
+
+// appendDiagnostics summarizes the problems met during the analysis.
+func appendDiagnostics(builder *strings.Builder, rootPath string, diagnostics []model.Diagnostic) {
+	counts := make(map[string]int)
+	for _, d := range diagnostics {
+		counts[d.Kind]++
+	}
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	summary := make([]string, len(kinds))
+	for i, kind := range kinds {
+		summary[i] = fmt.Sprintf("%d %s", counts[kind], kind)
+	}
+
+	builder.WriteString("🩺 Diagnostics\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d problem(s): %s\n", len(diagnostics), strings.Join(summary, ", ")))
+	for _, d := range diagnostics {
+		location := "(analysis)"
+		if d.Path != "" {
+			location = relativePath(rootPath, d.Path)
+		}
+		builder.WriteString(fmt.Sprintf("  - %s  %s/%s: %s\n", location, d.Phase, d.Kind, d.Message))
+	}
+	builder.WriteString("\n")
+}
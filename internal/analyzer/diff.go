@@ -0,0 +1,225 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// Compare reports the structural difference between two analyses: files and
+// elements added, removed or moved, signature changes, per-language deltas
+// and dependency edges that appeared or disappeared.
+func Compare(oldResult, newResult *model.AnalysisResult) *model.AnalysisDiff {
+	diff := &model.AnalysisDiff{
+		OldRoot:        oldResult.Root.Path,
+		NewRoot:        newResult.Root.Path,
+		LanguageDeltas: make(map[string]model.LanguageDelta),
+	}
+	oldFiles := filesByRelPath(oldResult.Root)
+	newFiles := filesByRelPath(newResult.Root)
+
+	var added, removed []model.DiffElement
+	for _, relPath := range sortedKeys(newFiles) {
+		newNode := newFiles[relPath]
+		oldNode, existed := oldFiles[relPath]
+		if !existed {
+			diff.FilesAdded = append(diff.FilesAdded, relPath)
+			oldNode = &model.Node{}
+		}
+		for _, change := range diffElements(oldNode.CodeElements, newNode.CodeElements) {
+			switch change.Change {
+			case ElementAdded:
+				added = append(added, model.DiffElement{Path: relPath, Type: change.Type, Name: change.Name, Line: change.Line, Signature: change.Signature})
+			case ElementRemoved:
+				removed = append(removed, model.DiffElement{Path: relPath, Type: change.Type, Name: change.Name, Signature: change.Signature})
+			case ElementSignatureChanged:
+				diff.SignatureChanges = append(diff.SignatureChanges, model.SignatureChange{
+					Path:         relPath,
+					Type:         change.Type,
+					Name:         change.Name,
+					Line:         change.Line,
+					OldSignature: change.OldSignature,
+					Signature:    change.Signature,
+				})
+			}
+		}
+	}
+	for _, relPath := range sortedKeys(oldFiles) {
+		if _, exists := newFiles[relPath]; exists {
+			continue
+		}
+		diff.FilesRemoved = append(diff.FilesRemoved, relPath)
+		for _, el := range oldFiles[relPath].CodeElements {
+			removed = append(removed, model.DiffElement{Path: relPath, Type: el.Type, Name: el.Name, Signature: el.Signature})
+		}
+	}
+	diff.ElementsMoved, diff.ElementsAdded, diff.ElementsRemoved = pairMoves(added, removed)
+
+	for lang, stats := range oldResult.Analytics.PerLanguageStats {
+		delta := diff.LanguageDeltas[lang]
+		delta.OldFiles, delta.OldLOC = stats.FileCount, stats.LOC
+		diff.LanguageDeltas[lang] = delta
+	}
+	for lang, stats := range newResult.Analytics.PerLanguageStats {
+		delta := diff.LanguageDeltas[lang]
+		delta.NewFiles, delta.NewLOC = stats.FileCount, stats.LOC
+		diff.LanguageDeltas[lang] = delta
+	}
+
+	oldEdges := dependencyEdges(oldFiles)
+	newEdges := dependencyEdges(newFiles)
+	diff.DependenciesAdded = edgesMissingFrom(newEdges, oldEdges)
+	diff.DependenciesRemoved = edgesMissingFrom(oldEdges, newEdges)
+	return diff
+}
+
+// filesByRelPath indexes the file nodes of a tree by their slash-separated
+// path relative to the root, so analyses of different checkouts line up.
+func filesByRelPath(root *model.Node) map[string]*model.Node {
+	files := make(map[string]*model.Node)
+	for _, node := range collectFileNodes(root) {
+		relPath := relativePath(root.Path, node.Path)
+		files[filepath.ToSlash(relPath)] = node
+	}
+	return files
+}
+
+// sortedKeys returns the keys of a file index in lexical order.
+func sortedKeys(files map[string]*model.Node) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pairMoves matches elements removed from one file with elements of the same
+// type and name added to another, and returns the moves together with the
+// additions and removals left over.
+func pairMoves(added, removed []model.DiffElement) ([]model.MovedElement, []model.DiffElement, []model.DiffElement) {
+	removedByKey := make(map[elementKey][]int)
+	for i, el := range removed {
+		key := elementKey{Type: el.Type, Name: el.Name}
+		removedByKey[key] = append(removedByKey[key], i)
+	}
+
+	var moves []model.MovedElement
+	var addedLeft []model.DiffElement
+	paired := make(map[int]bool)
+	for _, el := range added {
+		match := -1
+		for _, idx := range removedByKey[elementKey{Type: el.Type, Name: el.Name}] {
+			if !paired[idx] && removed[idx].Path != el.Path {
+				match = idx
+				break
+			}
+		}
+		if match < 0 {
+			addedLeft = append(addedLeft, el)
+			continue
+		}
+		paired[match] = true
+		moves = append(moves, model.MovedElement{Type: el.Type, Name: el.Name, FromPath: removed[match].Path, ToPath: el.Path, Line: el.Line})
+	}
+
+	var removedLeft []model.DiffElement
+	for i, el := range removed {
+		if !paired[i] {
+			removedLeft = append(removedLeft, el)
+		}
+	}
+	return moves, addedLeft, removedLeft
+}
+
+// dependencyEdges lists the imports of every file as edges.
+func dependencyEdges(files map[string]*model.Node) map[model.DependencyEdge]bool {
+	edges := make(map[model.DependencyEdge]bool)
+	for relPath, node := range files {
+		for _, target := range node.Imports {
+			edges[model.DependencyEdge{Path: relPath, Target: target}] = true
+		}
+	}
+	return edges
+}
+
+// edgesMissingFrom returns the edges of a that are not in b, sorted.
+func edgesMissingFrom(a, b map[model.DependencyEdge]bool) []model.DependencyEdge {
+	var edges []model.DependencyEdge
+	for edge := range a {
+		if !b[edge] {
+			edges = append(edges, edge)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Path != edges[j].Path {
+			return edges[i].Path < edges[j].Path
+		}
+		return edges[i].Target < edges[j].Target
+	})
+	return edges
+}
+
+// FormatDiff renders a structural diff as a human-readable changelog.
+func FormatDiff(diff *model.AnalysisDiff) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Structural diff: %s → %s\n\n", diff.OldRoot, diff.NewRoot))
+
+	builder.WriteString("Files\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d added, %d removed\n", len(diff.FilesAdded), len(diff.FilesRemoved)))
+	for _, path := range diff.FilesAdded {
+		builder.WriteString(fmt.Sprintf("  + %s\n", path))
+	}
+	for _, path := range diff.FilesRemoved {
+		builder.WriteString(fmt.Sprintf("  - %s\n", path))
+	}
+	builder.WriteString("\n")
+
+	builder.WriteString("Elements\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d added, %d removed, %d moved, %d signature changes\n",
+		len(diff.ElementsAdded), len(diff.ElementsRemoved), len(diff.ElementsMoved), len(diff.SignatureChanges)))
+	for _, el := range diff.ElementsAdded {
+		builder.WriteString(fmt.Sprintf("  + %s: %s (%s:L%d)\n", el.Type, el.Name, el.Path, el.Line))
+	}
+	for _, el := range diff.ElementsRemoved {
+		builder.WriteString(fmt.Sprintf("  - %s: %s (%s)\n", el.Type, el.Name, el.Path))
+	}
+	for _, move := range diff.ElementsMoved {
+		builder.WriteString(fmt.Sprintf("  → %s: %s moved %s → %s\n", move.Type, move.Name, move.FromPath, move.ToPath))
+	}
+	for _, change := range diff.SignatureChanges {
+		builder.WriteString(fmt.Sprintf("  ~ %s: %s (%s:L%d)\n      %s\n    → %s\n",
+			change.Type, change.Name, change.Path, change.Line, change.OldSignature, change.Signature))
+	}
+	builder.WriteString("\n")
+
+	builder.WriteString("Language Breakdown\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	langs := make([]string, 0, len(diff.LanguageDeltas))
+	for lang := range diff.LanguageDeltas {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		delta := diff.LanguageDeltas[lang]
+		builder.WriteString(fmt.Sprintf("▶ %-12s %d files (%+d), %d LOC (%+d)\n",
+			lang, delta.NewFiles, delta.NewFiles-delta.OldFiles, delta.NewLOC, delta.NewLOC-delta.OldLOC))
+	}
+	builder.WriteString("\n")
+
+	builder.WriteString("Dependency Edges\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d added, %d removed\n", len(diff.DependenciesAdded), len(diff.DependenciesRemoved)))
+	for _, edge := range diff.DependenciesAdded {
+		builder.WriteString(fmt.Sprintf("  + %s → %s\n", edge.Path, edge.Target))
+	}
+	for _, edge := range diff.DependenciesRemoved {
+		builder.WriteString(fmt.Sprintf("  - %s → %s\n", edge.Path, edge.Target))
+	}
+	return builder.String()
+}
@@ -0,0 +1,236 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// DefaultDuplicateSimilarity is the similarity at which two function bodies
+// are reported as near copies unless configured otherwise.
+const DefaultDuplicateSimilarity = 0.8
+
+// maxPostings bounds how many functions may share a winnowing hash for it to
+// count: hashes found everywhere are boilerplate, not evidence of copying.
+const maxPostings = 50
+
+// maxStreamedFunctions bounds how many functions a streamed analysis
+// indexes for duplicate detection. Every indexed function keeps its
+// fingerprint until the end, so without a bound the index would grow with
+// the tree that --stream promises to handle in bounded memory.
+const maxStreamedFunctions = 20000
+
+// duplicateIndex collects the fingerprinted functions of an analysis.
+type duplicateIndex struct {
+	minSimilarity float64
+	functions     []model.DuplicateFunction
+	prints        []*model.BodyFingerprint
+	// limit caps the number of functions indexed, if positive; the
+	// functions found after that are only counted in dropped.
+	limit   int
+	dropped int
+}
+
+// newDuplicateIndex returns the index configured by opts, holding at most
+// limit functions if limit is positive, or nil when duplicate detection is
+// disabled.
+func newDuplicateIndex(opts Options, limit int) *duplicateIndex {
+	if opts.DuplicateSimilarity <= 0 {
+		return nil
+	}
+	return &duplicateIndex{minSimilarity: opts.DuplicateSimilarity, limit: limit}
+}
+
+// add indexes the fingerprinted functions of a file node.
+func (d *duplicateIndex) add(node *model.Node) {
+	if d == nil {
+		return
+	}
+	for _, el := range node.CodeElements {
+		if el.Fingerprint == nil {
+			continue
+		}
+		if d.limit > 0 && len(d.functions) >= d.limit {
+			d.dropped++
+			continue
+		}
+		d.functions = append(d.functions, model.DuplicateFunction{
+			Path:    node.Path,
+			Name:    el.Name,
+			Type:    el.Type,
+			Line:    el.Line,
+			EndLine: el.Fingerprint.EndLine,
+			Tokens:  el.Fingerprint.Tokens,
+		})
+		d.prints = append(d.prints, el.Fingerprint)
+	}
+}
+
+// limitDiagnostic reports the functions left out of duplicate detection
+// because the index was full, if any.
+func (d *duplicateIndex) limitDiagnostic() (model.Diagnostic, bool) {
+	if d == nil || d.dropped == 0 {
+		return model.Diagnostic{}, false
+	}
+	return model.Diagnostic{
+		Phase:   PhaseDuplicates,
+		Kind:    KindLimit,
+		Message: fmt.Sprintf("only the first %d functions were compared for duplicates; %d later ones were not", d.limit, d.dropped),
+	}, true
+}
+
+// similarPair is two functions whose bodies are alike.
+type similarPair struct {
+	a, b       int
+	similarity float64
+}
+
+// clusters groups the indexed functions into clusters of copies and near
+// copies, largest first.
+func (d *duplicateIndex) clusters() []model.DuplicateCluster {
+	if d == nil || len(d.functions) < 2 {
+		return nil
+	}
+	pairs := d.similarPairs()
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	parent := make([]int, len(d.functions))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, p := range pairs {
+		parent[find(p.a)] = find(p.b)
+	}
+
+	members := make(map[int][]int)
+	minSimilarity := make(map[int]float64)
+	for _, p := range pairs {
+		root := find(p.a)
+		if s, ok := minSimilarity[root]; !ok || p.similarity < s {
+			minSimilarity[root] = p.similarity
+		}
+	}
+	for i := range d.functions {
+		if _, ok := minSimilarity[find(i)]; ok {
+			members[find(i)] = append(members[find(i)], i)
+		}
+	}
+
+	var clusters []model.DuplicateCluster
+	for root, ids := range members {
+		cluster := model.DuplicateCluster{Exact: true, Similarity: minSimilarity[root]}
+		for _, id := range ids {
+			cluster.Functions = append(cluster.Functions, d.functions[id])
+			if d.prints[id].Hash != d.prints[ids[0]].Hash {
+				cluster.Exact = false
+			}
+		}
+		sort.Slice(cluster.Functions, func(i, j int) bool {
+			a, b := cluster.Functions[i], cluster.Functions[j]
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			return a.Line < b.Line
+		})
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if a.Exact != b.Exact {
+			return a.Exact
+		}
+		if ta, tb := a.Functions[0].Tokens, b.Functions[0].Tokens; ta != tb {
+			return ta > tb
+		}
+		if a.Functions[0].Path != b.Functions[0].Path {
+			return a.Functions[0].Path < b.Functions[0].Path
+		}
+		return a.Functions[0].Line < b.Functions[0].Line
+	})
+	return clusters
+}
+
+// similarPairs finds the pairs of functions that are copies or whose
+// winnowing hashes overlap by at least the minimum similarity (Jaccard
+// index). Copies are paired with the first function of their kind only,
+// which is enough to join them into one cluster. A function is never paired
+// with one nested inside it.
+func (d *duplicateIndex) similarPairs() []similarPair {
+	var pairs []similarPair
+	firstByHash := make(map[uint64]int)
+	postings := make(map[uint64][]int)
+	for id, fp := range d.prints {
+		if first, ok := firstByHash[fp.Hash]; !ok {
+			firstByHash[fp.Hash] = id
+		} else if !d.nested(first, id) {
+			pairs = append(pairs, similarPair{a: first, b: id, similarity: 1})
+		}
+		for _, h := range fp.Winnow {
+			postings[h] = append(postings[h], id)
+		}
+	}
+	shared := make(map[[2]int]int)
+	for _, ids := range postings {
+		if len(ids) > maxPostings {
+			continue
+		}
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				shared[[2]int{ids[i], ids[j]}]++
+			}
+		}
+	}
+
+	for key, count := range shared {
+		a, b := key[0], key[1]
+		// Copies were paired above.
+		if d.prints[a].Hash == d.prints[b].Hash || d.nested(a, b) {
+			continue
+		}
+		union := len(d.prints[a].Winnow) + len(d.prints[b].Winnow) - count
+		similarity := float64(count) / float64(union)
+		if similarity >= d.minSimilarity {
+			pairs = append(pairs, similarPair{a: a, b: b, similarity: similarity})
+		}
+	}
+	return pairs
+}
+
+// nested reports whether one of two functions encloses the other.
+func (d *duplicateIndex) nested(a, b int) bool {
+	fa, fb := d.functions[a], d.functions[b]
+	if fa.Path != fb.Path {
+		return false
+	}
+	return (fa.Line <= fb.Line && fb.EndLine <= fa.EndLine) || (fb.Line <= fa.Line && fa.EndLine <= fb.EndLine)
+}
+
+// appendDuplicates lists the clusters of duplicated functions.
+func appendDuplicates(builder *strings.Builder, rootPath string, clusters []model.DuplicateCluster) {
+	builder.WriteString("🧬 Duplicate Code\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d cluster(s) of copied or near-copied functions.\n", len(clusters)))
+	for _, cluster := range clusters {
+		if cluster.Exact {
+			builder.WriteString(fmt.Sprintf("  Exact copies (%d functions, %d tokens):\n", len(cluster.Functions), cluster.Functions[0].Tokens))
+		} else {
+			builder.WriteString(fmt.Sprintf("  %.0f%% similar (%d functions):\n", cluster.Similarity*100, len(cluster.Functions)))
+		}
+		for _, fn := range cluster.Functions {
+			relPath := relativePath(rootPath, fn.Path)
+			builder.WriteString(fmt.Sprintf("    - %s:%d-%d %s\n", relPath, fn.Line, fn.EndLine, fn.Name))
+		}
+	}
+	builder.WriteString("\n")
+}
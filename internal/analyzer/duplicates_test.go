@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// hashRange returns the winnowing hashes from through to, inclusive.
+func hashRange(from, to uint64) []uint64 {
+	var hashes []uint64
+	for h := from; h <= to; h++ {
+		hashes = append(hashes, h)
+	}
+	return hashes
+}
+
+// fingerprinted returns a function element with the given body fingerprint.
+func fingerprinted(name string, line, endLine int, hash uint64, winnow []uint64) model.CodeElement {
+	return model.CodeElement{
+		Type:        "Function",
+		Name:        name,
+		Line:        line,
+		Fingerprint: &model.BodyFingerprint{Hash: hash, Tokens: 60, EndLine: endLine, Winnow: winnow},
+	}
+}
+
+func TestSimilarPairs(t *testing.T) {
+	d := newDuplicateIndex(Options{DuplicateSimilarity: 0.8}, 0)
+	d.add(&model.Node{Path: "/src/a.go", CodeElements: []model.CodeElement{
+		fingerprinted("copy1", 1, 10, 7, hashRange(1, 10)),
+		// Encloses inner, which has the same body.
+		fingerprinted("outer", 20, 60, 8, hashRange(200, 210)),
+		fingerprinted("inner", 30, 40, 8, hashRange(200, 210)),
+	}})
+	d.add(&model.Node{Path: "/src/b.go", CodeElements: []model.CodeElement{
+		fingerprinted("copy2", 1, 10, 7, hashRange(1, 10)),
+		// 9 of 11 distinct hashes shared with the copies: 82% similar.
+		fingerprinted("near", 20, 30, 9, append(hashRange(1, 9), 99)),
+		// 7 of 13 shared: 54% similar.
+		fingerprinted("far", 40, 50, 10, append(hashRange(1, 7), 50, 51, 52)),
+		// Shares a hash with outer and inner, but is otherwise different.
+		fingerprinted("other", 60, 70, 11, append(hashRange(300, 309), 200)),
+	}})
+
+	type pair struct {
+		a, b       string
+		similarity float64
+	}
+	var got []pair
+	for _, p := range d.similarPairs() {
+		a, b := d.functions[p.a].Name, d.functions[p.b].Name
+		if a > b {
+			a, b = b, a
+		}
+		got = append(got, pair{a, b, float64(int(p.similarity*100)) / 100})
+	}
+	sort.Slice(got, func(i, j int) bool {
+		if got[i].a != got[j].a {
+			return got[i].a < got[j].a
+		}
+		return got[i].b < got[j].b
+	})
+	want := []pair{
+		{"copy1", "copy2", 1},
+		{"copy1", "near", 0.81},
+		{"copy2", "near", 0.81},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("similarPairs = %v, want %v", got, want)
+	}
+}
+
+func TestSimilarPairsSkipsCommonHashes(t *testing.T) {
+	d := newDuplicateIndex(Options{DuplicateSimilarity: 0.5}, 0)
+	// Every function shares hashes 1 and 2, as boilerplate would. Counted,
+	// they would make every pair 2 of 4 similar.
+	for i := 0; i <= maxPostings; i++ {
+		d.add(&model.Node{Path: "/src/gen.go", CodeElements: []model.CodeElement{
+			fingerprinted("f", i*10+1, i*10+5, uint64(1000+i), []uint64{1, 2, uint64(2000 + i)}),
+		}})
+	}
+	if pairs := d.similarPairs(); len(pairs) != 0 {
+		t.Errorf("similarPairs = %d pairs, want none", len(pairs))
+	}
+}
+
+func TestDuplicateIndexLimit(t *testing.T) {
+	d := newDuplicateIndex(Options{DuplicateSimilarity: 0.8}, 2)
+	d.add(&model.Node{Path: "/src/a.go", CodeElements: []model.CodeElement{
+		fingerprinted("a", 1, 10, 7, hashRange(1, 10)),
+		{Type: "Function", Name: "unfingerprinted", Line: 12},
+		fingerprinted("b", 20, 30, 7, hashRange(1, 10)),
+		fingerprinted("c", 40, 50, 7, hashRange(1, 10)),
+	}})
+	if len(d.functions) != 2 {
+		t.Errorf("indexed %d functions, want 2", len(d.functions))
+	}
+	diagnostic, ok := d.limitDiagnostic()
+	if !ok || diagnostic.Phase != PhaseDuplicates || diagnostic.Kind != KindLimit {
+		t.Errorf("limitDiagnostic = %+v, %v; want a limit diagnostic", diagnostic, ok)
+	}
+
+	if _, ok := newDuplicateIndex(Options{DuplicateSimilarity: 0.8}, 0).limitDiagnostic(); ok {
+		t.Error("an unbounded index reported a limit")
+	}
+}
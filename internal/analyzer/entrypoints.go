@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// ElementEntryPoint is the element type of a program's starting point: a Go,
+// Java or Rust main function, or a Python __main__ block.
+const ElementEntryPoint = "Entry Point"
+
+// shadowingTypes are element types that refine a plain function or method
+// found by another query; the plain element is dropped.
+var shadowingTypes = map[string]bool{ElementTest: true, ElementEntryPoint: true}
+
+// dropShadowed removes the elements that an element of a shadowing type
+// describes better: the same name on the same line.
+func dropShadowed(node *model.Node) {
+	shadowed := make(map[string]bool)
+	for _, el := range node.CodeElements {
+		if shadowingTypes[el.Type] {
+			shadowed[fmt.Sprintf("%d:%s", el.Line, el.Name)] = true
+		}
+	}
+	if len(shadowed) == 0 {
+		return
+	}
+	elements := node.CodeElements[:0]
+	for _, el := range node.CodeElements {
+		if !shadowingTypes[el.Type] && shadowed[fmt.Sprintf("%d:%s", el.Line, el.Name)] {
+			continue
+		}
+		elements = append(elements, el)
+	}
+	node.CodeElements = elements
+}
+
+// landmarkRanks orders the kinds of landmarks in the "Where to start"
+// section: programs first, then the applications of web frameworks, then
+// CLI commands. Element types that are not listed are not landmarks.
+var landmarkRanks = map[string]int{
+	ElementEntryPoint:         0,
+	"Spring Boot Application": 1,
+	"Express App":             1,
+	"FastAPI App":             1,
+	"Flask App":               1,
+	"Command":                 2,
+}
+
+// entryIndex collects the entry points and framework landmarks of an
+// analysis.
+type entryIndex struct {
+	entries []model.EntryPoint
+	// crates are the directories of the Cargo manifests, and binaries maps
+	// the source files of the binaries they declare to their names.
+	crates   []string
+	binaries map[string]string
+}
+
+// newEntryIndex returns an empty index that names Rust binaries after the
+// targets of the Cargo manifests.
+func newEntryIndex(manifests []model.Manifest) *entryIndex {
+	e := &entryIndex{binaries: make(map[string]string)}
+	for _, m := range manifests {
+		if filepath.Base(m.Path) != "Cargo.toml" {
+			continue
+		}
+		dir := filepath.Dir(m.Path)
+		e.crates = append(e.crates, dir)
+		if m.Module != "" {
+			e.binaries[filepath.Join(dir, "src", "main.rs")] = m.Module
+		}
+		for _, bin := range m.Binaries {
+			switch {
+			case bin.Name == "":
+			case bin.Path != "":
+				e.binaries[filepath.Join(dir, filepath.FromSlash(bin.Path))] = bin.Name
+			case bin.Name == m.Module:
+				e.binaries[filepath.Join(dir, "src", "main.rs")] = bin.Name
+			default:
+				e.binaries[filepath.Join(dir, "src", "bin", bin.Name+".rs")] = bin.Name
+				e.binaries[filepath.Join(dir, "src", "bin", bin.Name, "main.rs")] = bin.Name
+			}
+		}
+	}
+	// The innermost crate of a file comes first.
+	sort.Slice(e.crates, func(i, j int) bool { return len(e.crates[i]) > len(e.crates[j]) })
+	return e
+}
+
+// add records the landmarks of a file node.
+func (e *entryIndex) add(node *model.Node) {
+	if node.Skipped != "" || node.Status == git.StatusDeleted || isTestFile(node.Path, languageOf(node.Path)) {
+		return
+	}
+	for _, el := range node.CodeElements {
+		if _, ok := landmarkRanks[el.Type]; !ok {
+			continue
+		}
+		entry := model.EntryPoint{
+			Path: node.Path,
+			Kind: landmarkKind(node.Path, el.Type),
+			Type: el.Type,
+			Name: el.Name,
+			Line: el.Line,
+		}
+		if entry.Kind == rustBinary {
+			name, ok := e.rustBinary(node.Path)
+			if !ok {
+				continue
+			}
+			entry.Name = name
+		}
+		e.entries = append(e.entries, entry)
+	}
+}
+
+// rustBinary returns the name of the binary whose source file is path: a
+// target declared in Cargo.toml, src/main.rs, or a file Cargo discovers as
+// src/bin/<name>.rs or src/bin/<name>/main.rs. A main function elsewhere in
+// a crate, such as in a library module, starts no binary. Outside of any
+// crate the file name is used.
+func (e *entryIndex) rustBinary(path string) (string, bool) {
+	if name, ok := e.binaries[path]; ok {
+		return name, true
+	}
+	for _, crate := range e.crates {
+		rel, err := filepath.Rel(crate, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		switch {
+		case len(parts) == 3 && parts[0] == "src" && parts[1] == "bin":
+			return strings.TrimSuffix(parts[2], ".rs"), true
+		case len(parts) == 4 && parts[0] == "src" && parts[1] == "bin" && parts[3] == "main.rs":
+			return parts[2], true
+		}
+		return "", false
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".rs"), true
+}
+
+// languageOf returns the name of the language of a file, or "".
+func languageOf(path string) string {
+	lang, _ := GetLanguageByFileExtension(path)
+	return lang.Name
+}
+
+// rustBinary is the kind of the entry point of a Rust executable.
+const rustBinary = "Rust binary"
+
+// landmarkKind describes a landmark for readers, naming the kind of
+// program an entry point starts.
+func landmarkKind(path, elementType string) string {
+	switch {
+	case elementType == "Command":
+		return "Cobra command"
+	case elementType != ElementEntryPoint:
+		return elementType
+	}
+	switch languageOf(path) {
+	case "Go":
+		return "Go main package"
+	case "Python":
+		return "Python script"
+	case "Java":
+		return "Java main class"
+	case "Rust":
+		if filepath.Base(path) == "build.rs" {
+			return "Rust build script"
+		}
+		return rustBinary
+	}
+	return elementType
+}
+
+// list returns the landmarks, most important first.
+func (e *entryIndex) list() []model.EntryPoint {
+	sort.SliceStable(e.entries, func(i, j int) bool {
+		a, b := e.entries[i], e.entries[j]
+		if ra, rb := landmarkRanks[a.Type], landmarkRanks[b.Type]; ra != rb {
+			return ra < rb
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return e.entries
+}
+
+// appendEntryPoints lists where a reader should start: entry points, web
+// applications and commands.
+func appendEntryPoints(builder *strings.Builder, rootPath string, entries []model.EntryPoint) {
+	builder.WriteString("🚪 Where to start\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	for _, entry := range entries {
+		relPath := relativePath(rootPath, entry.Path)
+		line := fmt.Sprintf("  - %s:%d  %s", relPath, entry.Line, entry.Kind)
+		switch entry.Type {
+		case ElementEntryPoint:
+			if entry.Kind == rustBinary {
+				line += fmt.Sprintf(" %q", entry.Name)
+			}
+		case "Command":
+			line += fmt.Sprintf(" %q", entry.Name)
+		default:
+			line += " " + entry.Name
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+func TestEntryIndexNamesRustBinaries(t *testing.T) {
+	root := t.TempDir()
+	crate := filepath.Join(root, "app")
+	manifests := []model.Manifest{
+		{Path: filepath.Join(root, "Cargo.toml"), Ecosystem: "cargo", Workspace: []string{"app"}},
+		{Path: filepath.Join(crate, "Cargo.toml"), Ecosystem: "cargo", Module: "app", Binaries: []model.Binary{
+			{Name: "app-server", Path: "cmd/server.rs"},
+			{Name: "migrate"},
+		}},
+	}
+	files := map[string]string{
+		"app/src/main.rs":           "app",
+		"app/cmd/server.rs":         "app-server",
+		"app/src/bin/migrate.rs":    "migrate",
+		"app/src/bin/seed.rs":       "seed",
+		"app/src/bin/bench/main.rs": "bench",
+		"app/src/util.rs":           "",
+		"xtask/main.rs":             "",
+		"app/build.rs":              "main",
+	}
+	entries := newEntryIndex(manifests)
+	for file := range files {
+		entries.add(&model.Node{
+			Path:         filepath.Join(root, filepath.FromSlash(file)),
+			CodeElements: []model.CodeElement{{Type: ElementEntryPoint, Name: "main", Line: 1}},
+		})
+	}
+	got := make(map[string]string)
+	for _, entry := range entries.list() {
+		rel, _ := filepath.Rel(root, entry.Path)
+		got[filepath.ToSlash(rel)] = entry.Name
+	}
+	want := make(map[string]string)
+	for file, name := range files {
+		if name != "" {
+			want[file] = name
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entry points = %v, want %v", got, want)
+	}
+}
+
+func TestEntryIndexOutsideCrates(t *testing.T) {
+	entries := newEntryIndex(nil)
+	entries.add(&model.Node{
+		Path:         filepath.Join(t.TempDir(), "tool.rs"),
+		CodeElements: []model.CodeElement{{Type: ElementEntryPoint, Name: "main", Line: 3}},
+	})
+	if list := entries.list(); len(list) != 1 || list[0].Name != "tool" || list[0].Kind != rustBinary {
+		t.Errorf("entry points = %+v, want the binary tool", list)
+	}
+}
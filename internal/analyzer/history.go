@@ -0,0 +1,180 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// DefaultHotFilesTop is how many of the hottest files the history report
+// lists.
+const DefaultHotFilesTop = 10
+
+// topAuthors is how many authors the history of a file names.
+const topAuthors = 3
+
+// historyWindow matches a window of history: a number of days, weeks,
+// months or years, e.g. 90d or 1y.
+var historyWindow = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// parseHistoryWindow returns the time a window ending at now starts at, or
+// the zero time for "all", the whole history.
+func parseHistoryWindow(window string, now time.Time) (time.Time, error) {
+	if window == "all" {
+		return time.Time{}, nil
+	}
+	m := historyWindow.FindStringSubmatch(window)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid history window '%s' (expected e.g. 90d, 12w, 6m, 1y or all)", window)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid history window '%s': %w", window, err)
+	}
+	switch m[2] {
+	case "d":
+		return now.AddDate(0, 0, -n), nil
+	case "w":
+		return now.AddDate(0, 0, -7*n), nil
+	case "m":
+		return now.AddDate(0, -n, 0), nil
+	}
+	return now.AddDate(-n, 0, 0), nil
+}
+
+// fileHistory holds the git history of a tree and ranks its hot files.
+type fileHistory struct {
+	root   string
+	report model.HistoryReport
+	files  map[string]*git.FileChurn
+	hot    []model.HotFile
+}
+
+// loadHistory reads the history of the tree at root over the window of
+// opts.History, or returns nil when it was not requested. When git fails
+// the problem is reported to findings and the history is left empty.
+func loadHistory(ctx context.Context, root string, opts Options, findings *collector) (*fileHistory, error) {
+	if opts.History == "" {
+		return nil, nil
+	}
+	since, err := parseHistoryWindow(opts.History, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	h := &fileHistory{root: root, report: model.HistoryReport{Window: opts.History}}
+	if !since.IsZero() {
+		h.report.Since = since.Format("2006-01-02")
+	}
+	h.files, h.report.Commits, err = git.History(ctx, root, since)
+	if err != nil && ctx.Err() == nil {
+		findings.addDiagnostics(model.Diagnostic{Phase: PhaseHistory, Kind: KindGit, Message: err.Error()})
+	}
+	return h, nil
+}
+
+// annotate sets the history of a file node, if it changed in the window.
+func (h *fileHistory) annotate(node *model.Node) {
+	if h == nil || node.IsDir {
+		return
+	}
+	relPath, err := filepath.Rel(h.root, node.Path)
+	if err != nil {
+		return
+	}
+	churn, ok := h.files[filepath.ToSlash(relPath)]
+	if !ok {
+		return
+	}
+	history := &model.FileHistory{
+		Commits:      churn.Commits,
+		LastModified: churn.LastCommit.Format("2006-01-02"),
+		LinesAdded:   churn.Added,
+		LinesDeleted: churn.Deleted,
+	}
+	for name, commits := range churn.Authors {
+		history.TopAuthors = append(history.TopAuthors, model.AuthorCommits{Name: name, Commits: commits})
+	}
+	sort.Slice(history.TopAuthors, func(i, j int) bool {
+		a, b := history.TopAuthors[i], history.TopAuthors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+	if len(history.TopAuthors) > topAuthors {
+		history.TopAuthors = history.TopAuthors[:topAuthors]
+	}
+	node.History = history
+}
+
+// add ranks a file node by its churn times the cognitive complexity of its
+// functions.
+func (h *fileHistory) add(node *model.Node) {
+	if h == nil || node.History == nil || node.Skipped != "" || node.Status == git.StatusDeleted {
+		return
+	}
+	complexity := 0
+	for _, el := range node.CodeElements {
+		complexity += el.Cognitive
+	}
+	if complexity == 0 {
+		return
+	}
+	h.hot = append(h.hot, model.HotFile{
+		Path:         node.Path,
+		Commits:      node.History.Commits,
+		LinesChanged: node.History.LinesAdded + node.History.LinesDeleted,
+		Complexity:   complexity,
+		Score:        node.History.Commits * complexity,
+	})
+}
+
+// result returns the history report with the hottest files, or nil when
+// history was not requested.
+func (h *fileHistory) result() *model.HistoryReport {
+	if h == nil {
+		return nil
+	}
+	sort.SliceStable(h.hot, func(i, j int) bool {
+		a, b := h.hot[i], h.hot[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Path < b.Path
+	})
+	if len(h.hot) > DefaultHotFilesTop {
+		h.hot = h.hot[:DefaultHotFilesTop]
+	}
+	report := h.report
+	report.HotFiles = h.hot
+	return &report
+}
+
+// appendHistory lists the hottest files of the history window.
+func appendHistory(builder *strings.Builder, rootPath string, report *model.HistoryReport) {
+	window := "all history"
+	if report.Since != "" {
+		window = fmt.Sprintf("since %s", report.Since)
+	}
+	builder.WriteString(fmt.Sprintf("🌡️ Hot Files (%d commits, %s)\n", report.Commits, window))
+	builder.WriteString("────────────────────────────────────────\n")
+	if len(report.HotFiles) == 0 {
+		builder.WriteString("No complex file changed in this window.\n\n")
+		return
+	}
+	builder.WriteString("Ranked by commits × cognitive complexity.\n")
+	builder.WriteString(fmt.Sprintf("  %6s %7s %10s %7s  %s\n", "score", "commits", "complexity", "changed", "file"))
+	for _, file := range report.HotFiles {
+		relPath := relativePath(rootPath, file.Path)
+		builder.WriteString(fmt.Sprintf("  %6d %7d %10d %7d  %s\n", file.Score, file.Commits, file.Complexity, file.LinesChanged, relPath))
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import "github.com/harsh-apk/groot/internal/model"
+
+// previousAnalysis indexes an earlier analysis so unchanged files can keep
+// their parse results.
+type previousAnalysis struct {
+	files       map[string]*model.Node
+	redactions  map[string][]model.Redaction
+	diagnostics map[string][]model.Diagnostic
+	changed     map[string]bool
+}
+
+// newPreviousAnalysis returns the index for opts.Previous, or nil when the
+// analysis starts from scratch.
+func newPreviousAnalysis(opts Options) *previousAnalysis {
+	if opts.Previous == nil || opts.Previous.Root == nil {
+		return nil
+	}
+	prev := &previousAnalysis{
+		files:       make(map[string]*model.Node),
+		redactions:  make(map[string][]model.Redaction),
+		diagnostics: make(map[string][]model.Diagnostic),
+		changed:     opts.Changed,
+	}
+	for _, node := range collectFileNodes(opts.Previous.Root) {
+		prev.files[node.Path] = node
+	}
+	for _, r := range opts.Previous.Redactions {
+		prev.redactions[r.Path] = append(prev.redactions[r.Path], r)
+	}
+	for _, d := range opts.Previous.Diagnostics {
+		if d.Path != "" {
+			prev.diagnostics[d.Path] = append(prev.diagnostics[d.Path], d)
+		}
+	}
+	return prev
+}
+
+// reuse copies the parse results of node's previous version into it and
+// reports whether it did. Files marked as changed are never reused.
+func (p *previousAnalysis) reuse(node *model.Node, findings *collector) bool {
+	if p == nil || p.changed[node.Path] {
+		return false
+	}
+	old, ok := p.files[node.Path]
+	if !ok || old.Status != node.Status || old.OldPath != node.OldPath {
+		return false
+	}
+	node.LOC = old.LOC
+	node.CodeLines = old.CodeLines
+	node.CommentLines = old.CommentLines
+	node.BlankLines = old.BlankLines
+	node.CodeElements = old.CodeElements
+	node.Imports = old.Imports
+	node.Routes = old.Routes
+	node.Annotations = old.Annotations
+	node.Skeleton = old.Skeleton
+	node.Hash = old.Hash
+	node.ElementChanges = old.ElementChanges
+	node.Skipped = old.Skipped
+	findings.addRedactions(p.redactions[node.Path])
+	findings.addDiagnostics(p.diagnostics[node.Path]...)
+	return true
+}
@@ -0,0 +1,345 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// moduleSet holds the modules of a tree: every directory with a dependency
+// manifest is the root of one.
+type moduleSet struct {
+	root    string
+	modules []model.Module
+	// byDir maps the root directory of each module to its index.
+	byDir map[string]int
+}
+
+// newModuleSet builds the modules of the tree at root from its manifests,
+// linking each module to the local modules it depends on and to the
+// members of the workspace it declares.
+func newModuleSet(root string, manifests []model.Manifest) *moduleSet {
+	s := &moduleSet{root: root, byDir: make(map[string]int)}
+	manifestsByDir := make(map[string][]model.Manifest)
+	var dirs []string
+	for _, m := range manifests {
+		dir := filepath.Dir(m.Path)
+		if _, seen := manifestsByDir[dir]; !seen {
+			dirs = append(dirs, dir)
+		}
+		manifestsByDir[dir] = append(manifestsByDir[dir], m)
+	}
+	sort.Strings(dirs)
+
+	names := make(map[string]int)
+	for _, dir := range dirs {
+		module := model.Module{Path: dir}
+		for _, m := range manifestsByDir[dir] {
+			if module.Name == "" {
+				module.Name = m.Module
+			}
+			if !containsString(module.Ecosystems, m.Ecosystem) {
+				module.Ecosystems = append(module.Ecosystems, m.Ecosystem)
+			}
+		}
+		if module.Name == "" {
+			module.Name = s.relDir(dir)
+		}
+		if module.Name == "." {
+			module.Name = filepath.Base(dir)
+		}
+		names[module.Name]++
+		s.byDir[dir] = len(s.modules)
+		s.modules = append(s.modules, module)
+	}
+	// Modules that share a name are told apart by their directories.
+	for i := range s.modules {
+		if names[s.modules[i].Name] > 1 {
+			s.modules[i].Name = s.relDir(s.modules[i].Path)
+		}
+	}
+
+	for i := range s.modules {
+		module := &s.modules[i]
+		for _, m := range manifestsByDir[module.Path] {
+			for _, dep := range m.Dependencies {
+				if local := s.resolve(module.Path, m.Ecosystem, dep); local != "" && local != module.Name && !containsString(module.Dependencies, local) {
+					module.Dependencies = append(module.Dependencies, local)
+				}
+			}
+			for _, pattern := range m.Workspace {
+				for _, member := range s.members(module.Path, pattern) {
+					if member != module.Name && !containsString(module.Members, member) {
+						module.Members = append(module.Members, member)
+					}
+				}
+			}
+		}
+		sort.Strings(module.Dependencies)
+		sort.Strings(module.Members)
+	}
+	return s
+}
+
+// resolve returns the name of the module of the tree that a dependency of
+// the module at dir refers to, by name or by path, or "" when it is a
+// third-party library.
+func (s *moduleSet) resolve(dir, ecosystem string, dep model.Dependency) string {
+	if path, ok := strings.CutPrefix(dep.Version, "path:"); ok {
+		if i, ok := s.byDir[filepath.Join(dir, filepath.FromSlash(path))]; ok {
+			return s.modules[i].Name
+		}
+	}
+	name := normalizeModuleName(ecosystem, dep.Name)
+	for _, module := range s.modules {
+		if containsString(module.Ecosystems, ecosystem) && normalizeModuleName(ecosystem, module.Name) == name {
+			return module.Name
+		}
+	}
+	return ""
+}
+
+// normalizeModuleName folds the spellings a package index treats as the
+// same name: Python package names ignore case and separators.
+func normalizeModuleName(ecosystem, name string) string {
+	if ecosystem != "pypi" {
+		return name
+	}
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+}
+
+// members returns the names of the modules below dir whose directories
+// match a workspace member pattern.
+func (s *moduleSet) members(dir, pattern string) []string {
+	patternParts := strings.Split(path.Clean(strings.TrimPrefix(pattern, "./")), "/")
+	var names []string
+	for _, module := range s.modules {
+		relPath, err := filepath.Rel(dir, module.Path)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if matchSegments(patternParts, strings.Split(filepath.ToSlash(relPath), "/")) {
+			names = append(names, module.Name)
+		}
+	}
+	return names
+}
+
+// matchSegments matches the directories of a path against those of a glob,
+// where a ** directory matches any number of directories, none included.
+func matchSegments(pattern, dirs []string) bool {
+	if len(pattern) == 0 {
+		return len(dirs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(dirs); i++ {
+			if matchSegments(pattern[1:], dirs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(dirs) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], dirs[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], dirs[1:])
+}
+
+// of returns the name of the module a path belongs to: the one rooted at
+// the closest directory above it, or "" when there is none.
+func (s *moduleSet) of(path string) string {
+	for dir := path; ; {
+		if i, ok := s.byDir[dir]; ok {
+			return s.modules[i].Name
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// selectModule returns the names of the module called name, or rooted at
+// the relative directory name, and of the local modules it depends on,
+// directly or not.
+func (s *moduleSet) selectModule(name string) (map[string]bool, error) {
+	start := ""
+	for _, module := range s.modules {
+		if module.Name == name || s.relDir(module.Path) == filepath.Clean(name) {
+			start = module.Name
+			break
+		}
+	}
+	if start == "" {
+		available := make([]string, len(s.modules))
+		for i, module := range s.modules {
+			available[i] = module.Name
+		}
+		if len(available) == 0 {
+			return nil, fmt.Errorf("unknown module '%s': no modules found", name)
+		}
+		return nil, fmt.Errorf("unknown module '%s'; modules are: %s", name, strings.Join(available, ", "))
+	}
+
+	selected := make(map[string]bool)
+	pending := []string{start}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		if selected[current] {
+			continue
+		}
+		selected[current] = true
+		for _, module := range s.modules {
+			if module.Name == current {
+				pending = append(pending, module.Dependencies...)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// list returns the modules, restricted to selected unless it is nil.
+func (s *moduleSet) list(selected map[string]bool) []model.Module {
+	var modules []model.Module
+	for _, module := range s.modules {
+		if selected == nil || selected[module.Name] {
+			modules = append(modules, module)
+		}
+	}
+	return modules
+}
+
+// manifests returns the manifests of the selected modules, or all of them
+// when selected is nil.
+func (s *moduleSet) manifests(manifests []model.Manifest, selected map[string]bool) []model.Manifest {
+	if selected == nil {
+		return manifests
+	}
+	var kept []model.Manifest
+	for _, m := range manifests {
+		if selected[s.of(m.Path)] {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// annotate sets the module of every file below node, and of the
+// directories that are the root of one.
+func (s *moduleSet) annotate(node *model.Node) {
+	if !node.IsDir {
+		node.Module = s.of(node.Path)
+	} else if i, ok := s.byDir[node.Path]; ok {
+		node.Module = s.modules[i].Name
+	}
+	for _, child := range node.Children {
+		s.annotate(child)
+	}
+}
+
+// moduleLabel marks a directory that is the root of a module.
+func moduleLabel(node *model.Node) string {
+	if !node.IsDir || node.Module == "" {
+		return ""
+	}
+	return " 🧩 " + node.Module
+}
+
+// relDir returns dir relative to the root, slash-separated.
+func (s *moduleSet) relDir(dir string) string {
+	relDir, err := filepath.Rel(s.root, dir)
+	if err != nil {
+		return dir
+	}
+	return filepath.ToSlash(relDir)
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneModules removes the files outside the selected modules from the
+// tree, and directories left empty.
+func pruneModules(node *model.Node, selected map[string]bool) {
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if child.IsDir {
+			pruneModules(child, selected)
+			if len(child.Children) == 0 {
+				continue
+			}
+		} else if !selected[child.Module] {
+			continue
+		}
+		children = append(children, child)
+	}
+	node.Children = children
+}
+
+// addModuleStats adds a file to the analytics of its module.
+func addModuleStats(stats *model.Analytics, node *model.Node, langName string) {
+	if node.Module == "" {
+		return
+	}
+	if stats.PerModuleStats == nil {
+		stats.PerModuleStats = make(map[string]model.ModuleStats)
+	}
+	moduleStats, ok := stats.PerModuleStats[node.Module]
+	if !ok {
+		moduleStats = model.ModuleStats{PerLanguageStats: make(map[string]model.LanguageStats)}
+	}
+	moduleStats.FileCount++
+	moduleStats.LOC += node.LOC
+	moduleStats.TotalElements += len(node.CodeElements)
+	langStats := moduleStats.PerLanguageStats[langName]
+	langStats.FileCount++
+	langStats.LOC += node.LOC
+	langStats.CodeLines += node.CodeLines
+	langStats.CommentLines += node.CommentLines
+	langStats.BlankLines += node.BlankLines
+	moduleStats.PerLanguageStats[langName] = langStats
+	stats.PerModuleStats[node.Module] = moduleStats
+}
+
+// appendModules lists the modules of a monorepo, how they depend on each
+// other and what each holds.
+func appendModules(builder *strings.Builder, rootPath string, modules []model.Module, stats model.Analytics) {
+	builder.WriteString("🧩 Modules\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	for _, module := range modules {
+		relDir := relativePath(rootPath, module.Path)
+		moduleStats := stats.PerModuleStats[module.Name]
+		builder.WriteString(fmt.Sprintf("▶ %s (%s; %s; %d files, %d LOC)\n", module.Name, filepath.ToSlash(relDir),
+			strings.Join(module.Ecosystems, ", "), moduleStats.FileCount, moduleStats.LOC))
+		if len(moduleStats.PerLanguageStats) > 0 {
+			langs := make([]string, 0, len(moduleStats.PerLanguageStats))
+			for langName, langStats := range moduleStats.PerLanguageStats {
+				langs = append(langs, fmt.Sprintf("%s %d", langName, langStats.LOC))
+			}
+			sort.Strings(langs)
+			builder.WriteString(fmt.Sprintf("  LOC by language: %s\n", strings.Join(langs, ", ")))
+		}
+		if len(module.Members) > 0 {
+			builder.WriteString(fmt.Sprintf("  Workspace members: %s\n", strings.Join(module.Members, ", ")))
+		}
+		if len(module.Dependencies) > 0 {
+			builder.WriteString(fmt.Sprintf("  Depends on: %s\n", strings.Join(module.Dependencies, ", ")))
+		}
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+func TestWorkspaceMemberPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"packages/*", []string{"a"}},
+		{"packages/**", []string{"a", "b", "c"}},
+		{"./packages/**/b", []string{"b"}},
+		{"**/c", []string{"c"}},
+		{"tools", []string{"tools"}},
+		{"packages/x*", nil},
+	}
+	root := t.TempDir()
+	dirs := map[string]string{
+		"packages/a":         "a",
+		"packages/group/b":   "b",
+		"packages/group/x/c": "c",
+		"tools":              "tools",
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			manifests := []model.Manifest{{Path: filepath.Join(root, "package.json"), Ecosystem: "npm", Module: "root", Workspace: []string{tt.pattern}}}
+			for dir, name := range dirs {
+				manifests = append(manifests, model.Manifest{Path: filepath.Join(root, filepath.FromSlash(dir), "package.json"), Ecosystem: "npm", Module: name})
+			}
+			s := newModuleSet(root, manifests)
+			members := s.modules[s.byDir[root]].Members
+			if !reflect.DeepEqual(members, tt.want) {
+				t.Errorf("members = %v, want %v", members, tt.want)
+			}
+		})
+	}
+}
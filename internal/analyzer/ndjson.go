@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// ndjsonWriter writes one JSON object per line: a model.FileRecord for every
+// file as soon as it is parsed, then a final model.AnalyticsRecord.
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	enc  *json.Encoder
+	root string
+}
+
+// NewNDJSONWriter returns a StreamWriter that writes the ndjson format to w.
+func NewNDJSONWriter(w io.Writer) StreamWriter {
+	buffered := bufio.NewWriter(w)
+	return &ndjsonWriter{w: buffered, enc: json.NewEncoder(buffered)}
+}
+
+func (n *ndjsonWriter) Begin(root string) error {
+	n.root = root
+	return nil
+}
+
+func (n *ndjsonWriter) WriteFile(node *model.Node) error {
+	record := model.FileRecord{
+		Type:           model.RecordFile,
+		Path:           n.relPath(node.Path),
+		LOC:            node.LOC,
+		CodeLines:      node.CodeLines,
+		CommentLines:   node.CommentLines,
+		BlankLines:     node.BlankLines,
+		Elements:       node.CodeElements,
+		Imports:        node.Imports,
+		Hash:           node.Hash,
+		Status:         node.Status,
+		ElementChanges: node.ElementChanges,
+		Skipped:        node.Skipped,
+		Module:         node.Module,
+		Routes:         node.Routes,
+		Annotations:    node.Annotations,
+		History:        node.History,
+	}
+	if record.Elements == nil {
+		record.Elements = []model.CodeElement{}
+	}
+	if lang, ok := GetLanguageByFileExtension(node.Path); ok {
+		record.Language = lang.Name
+	}
+	if node.OldPath != "" {
+		record.OldPath = n.relPath(node.OldPath)
+	}
+	if err := n.enc.Encode(record); err != nil {
+		return err
+	}
+	return n.w.Flush()
+}
+
+func (n *ndjsonWriter) Finish(result *model.AnalysisResult) error {
+	err := n.enc.Encode(model.AnalyticsRecord{
+		Type:             model.RecordAnalytics,
+		Root:             n.root,
+		Analytics:        result.Analytics,
+		Changes:          result.Changes,
+		Redactions:       relPaths(n, result.Redactions, func(r *model.Redaction) *string { return &r.Path }),
+		Incomplete:       result.Incomplete,
+		IncompleteReason: result.IncompleteReason,
+		Diagnostics:      relPaths(n, result.Diagnostics, func(d *model.Diagnostic) *string { return &d.Path }),
+		Complexity:       n.relComplexity(result.Complexity),
+		Duplicates:       n.relDuplicates(result.Duplicates),
+		Tests:            n.relTests(result.Tests),
+		Dependencies:     relPaths(n, result.Dependencies, func(m *model.Manifest) *string { return &m.Path }),
+		Modules:          relPaths(n, result.Modules, func(m *model.Module) *string { return &m.Path }),
+		EntryPoints:      relPaths(n, result.EntryPoints, func(e *model.EntryPoint) *string { return &e.Path }),
+		APISurface:       relPaths(n, result.APISurface, func(e *model.Endpoint) *string { return &e.File }),
+		TechDebt:         relPaths(n, result.TechDebt, func(a *model.FileAnnotation) *string { return &a.File }),
+		History:          n.relHistory(result.History),
+	})
+	if err != nil {
+		return err
+	}
+	return n.w.Flush()
+}
+
+// relPaths returns a copy of items in which the path that field selects is
+// relative to the root. Empty paths stay empty.
+func relPaths[T any](n *ndjsonWriter, items []T, field func(*T) *string) []T {
+	rel := make([]T, len(items))
+	copy(rel, items)
+	for i := range rel {
+		if path := field(&rel[i]); *path != "" {
+			*path = n.relPath(*path)
+		}
+	}
+	return rel
+}
+
+// relComplexity returns a copy of report with relative paths.
+func (n *ndjsonWriter) relComplexity(report *model.ComplexityReport) *model.ComplexityReport {
+	if report == nil {
+		return nil
+	}
+	rel := *report
+	rel.Top = relPaths(n, report.Top, func(fn *model.ComplexFunction) *string { return &fn.Path })
+	return &rel
+}
+
+// relDuplicates returns a copy of clusters with relative paths.
+func (n *ndjsonWriter) relDuplicates(clusters []model.DuplicateCluster) []model.DuplicateCluster {
+	rel := make([]model.DuplicateCluster, len(clusters))
+	for i, cluster := range clusters {
+		cluster.Functions = relPaths(n, cluster.Functions, func(fn *model.DuplicateFunction) *string { return &fn.Path })
+		rel[i] = cluster
+	}
+	return rel
+}
+
+// relTests returns a copy of report with relative paths.
+func (n *ndjsonWriter) relTests(report *model.TestReport) *model.TestReport {
+	if report == nil {
+		return nil
+	}
+	self := func(path *string) *string { return path }
+	rel := *report
+	rel.Mappings = make([]model.TestMapping, len(report.Mappings))
+	for i, mapping := range report.Mappings {
+		mapping.Test = n.relPath(mapping.Test)
+		mapping.Sources = relPaths(n, mapping.Sources, self)
+		rel.Mappings[i] = mapping
+	}
+	rel.Untested = relPaths(n, report.Untested, self)
+	return &rel
+}
+
+// relHistory returns a copy of report with relative paths.
+func (n *ndjsonWriter) relHistory(report *model.HistoryReport) *model.HistoryReport {
+	if report == nil {
+		return nil
+	}
+	rel := *report
+	rel.HotFiles = relPaths(n, report.HotFiles, func(file *model.HotFile) *string { return &file.Path })
+	return &rel
+}
+
+// relPath makes a path slash-separated and relative to the analyzed root.
+func (n *ndjsonWriter) relPath(path string) string {
+	return filepath.ToSlash(relativePath(n.root, path))
+}
+
+// WriteNDJSON writes a complete analysis in the ndjson format.
+func WriteNDJSON(w io.Writer, result *model.AnalysisResult) error {
+	out := NewNDJSONWriter(w)
+	if err := out.Begin(result.Root.Path); err != nil {
+		return err
+	}
+	for _, node := range collectFileNodes(result.Root) {
+		if err := out.WriteFile(node); err != nil {
+			return err
+		}
+	}
+	return out.Finish(result)
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/redact"
+)
+
+// redactNode replaces secrets in everything emitted for a file node, its
+// element names, signatures, annotations, routes and skeleton, and returns
+// what was replaced.
+func redactNode(node *model.Node) []model.Redaction {
+	var found []model.Redaction
+	redactField := func(field *string, line int) {
+		text, matches := redact.Text(*field)
+		for _, m := range matches {
+			found = append(found, model.Redaction{Path: node.Path, Kind: m.Kind, Line: line})
+		}
+		*field = text
+	}
+	for i := range node.CodeElements {
+		el := &node.CodeElements[i]
+		redactField(&el.Name, el.Line)
+		redactField(&el.Signature, el.Line)
+	}
+	for i := range node.ElementChanges {
+		change := &node.ElementChanges[i]
+		redactField(&change.Name, change.Line)
+		redactField(&change.Signature, change.Line)
+		redactField(&change.OldSignature, change.Line)
+	}
+	for i := range node.Annotations {
+		annotation := &node.Annotations[i]
+		redactField(&annotation.Text, annotation.Line)
+	}
+	for i := range node.Routes {
+		route := &node.Routes[i]
+		redactField(&route.Path, route.Line)
+		redactField(&route.Handler, route.Line)
+	}
+	if node.Skeleton != "" {
+		skeleton, matches := redact.Text(node.Skeleton)
+		for _, m := range matches {
+			found = append(found, model.Redaction{Path: node.Path, Kind: m.Kind, Line: m.Line})
+		}
+		node.Skeleton = skeleton
+	}
+	return found
+}
+
+// redactManifest replaces secrets in the dependency versions of a manifest,
+// such as a token in a git+https URL, and returns what was replaced. Lines
+// are found by looking the versions up in content.
+func redactManifest(m *model.Manifest, content []byte) []model.Redaction {
+	var found []model.Redaction
+	for i := range m.Dependencies {
+		dep := &m.Dependencies[i]
+		text, matches := redact.Text(dep.Version)
+		if len(matches) == 0 {
+			continue
+		}
+		line := 0
+		// Versions such as git:<url> carry a prefix that is not in the file.
+		_, unprefixed, _ := strings.Cut(dep.Version, ":")
+		for _, written := range []string{dep.Version, unprefixed} {
+			if at := bytes.Index(content, []byte(written)); written != "" && at >= 0 {
+				line = bytes.Count(content[:at], []byte("\n")) + 1
+				break
+			}
+		}
+		for _, match := range matches {
+			found = append(found, model.Redaction{Path: m.Path, Kind: match.Kind, Line: line})
+		}
+		dep.Version = text
+	}
+	return found
+}
+
+// sortRedactions orders redactions by file and line for stable output.
+func sortRedactions(redactions []model.Redaction) {
+	sort.Slice(redactions, func(i, j int) bool {
+		if redactions[i].Path != redactions[j].Path {
+			return redactions[i].Path < redactions[j].Path
+		}
+		return redactions[i].Line < redactions[j].Line
+	})
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// apiSurface collects the HTTP endpoints of an analysis.
+type apiSurface struct {
+	endpoints []model.Endpoint
+}
+
+// newAPISurface returns an empty surface.
+func newAPISurface() *apiSurface {
+	return &apiSurface{}
+}
+
+// add records the routes of a file node. Routes registered by tests are
+// fixtures, not part of the API.
+func (a *apiSurface) add(node *model.Node) {
+	if node.Skipped != "" || node.Status == git.StatusDeleted || isTestFile(node.Path, languageOf(node.Path)) {
+		return
+	}
+	for _, route := range node.Routes {
+		a.endpoints = append(a.endpoints, model.Endpoint{File: node.Path, Route: route})
+	}
+}
+
+// list returns the endpoints by path, then method.
+func (a *apiSurface) list() []model.Endpoint {
+	sort.SliceStable(a.endpoints, func(i, j int) bool {
+		x, y := a.endpoints[i], a.endpoints[j]
+		if x.Path != y.Path {
+			return x.Path < y.Path
+		}
+		if x.Method != y.Method {
+			return x.Method < y.Method
+		}
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		return x.Line < y.Line
+	})
+	return a.endpoints
+}
+
+// appendAPISurface renders the endpoints as a table of method, path,
+// handler and where it is defined.
+func appendAPISurface(builder *strings.Builder, rootPath string, endpoints []model.Endpoint) {
+	builder.WriteString(fmt.Sprintf("🌐 API surface (%d endpoints)\n", len(endpoints)))
+	builder.WriteString("────────────────────────────────────────\n")
+	methodWidth, pathWidth, handlerWidth := len("METHOD"), len("PATH"), len("HANDLER")
+	for _, e := range endpoints {
+		methodWidth = max(methodWidth, len(e.Method))
+		pathWidth = max(pathWidth, len(e.Path))
+		handlerWidth = max(handlerWidth, len(e.Handler))
+	}
+	row := fmt.Sprintf("  %%-%ds  %%-%ds  %%-%ds  %%s\n", methodWidth, pathWidth, handlerWidth)
+	builder.WriteString(fmt.Sprintf(row, "METHOD", "PATH", "HANDLER", "DEFINED IN"))
+	for _, e := range endpoints {
+		relPath := relativePath(rootPath, e.File)
+		builder.WriteString(fmt.Sprintf(row, e.Method, e.Path, e.Handler, fmt.Sprintf("%s:%d", relPath, e.Line)))
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,282 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+	"github.com/harsh-apk/groot/internal/walker"
+)
+
+// StreamWriter receives the results of a streamed analysis as they become
+// available.
+type StreamWriter interface {
+	// Begin is called once with the absolute path of the analyzed directory.
+	Begin(root string) error
+	// WriteFile is called for each analyzed file, in lexical path order, as
+	// soon as it and every file before it have been processed. The node is
+	// not retained afterwards.
+	WriteFile(node *model.Node) error
+	// Finish is called once with the totals. The result's Root has no children.
+	Finish(result *model.AnalysisResult) error
+}
+
+// errStreamStopped ends the walk once the writer has failed.
+var errStreamStopped = errors.New("stream stopped")
+
+// pendingFile is a file travelling through the streaming pipeline; done is
+// closed once a worker has processed it.
+type pendingFile struct {
+	node *model.Node
+	done chan struct{}
+}
+
+// Stream analyzes the tree at rootPath like Analyze, but walks, parses and
+// writes concurrently: files are handed to out in order while later files are
+// still being parsed, and only a bounded number of files are held in memory.
+// When ctx is done, the files written so far are followed by totals marked as
+// incomplete. The returned result holds those totals; its Root has no children.
+func Stream(ctx context.Context, rootPath string, skipDirs []string, includeExts []string, opts Options, out StreamWriter) (*model.AnalysisResult, error) {
+	startTime := time.Now()
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", rootPath, err)
+	}
+	walkOpts := walker.Options{
+		NoDefaultIgnores: opts.NoDefaultIgnores,
+		GitTracked:       opts.GitTracked,
+		Since:            opts.Since,
+		Staged:           opts.Staged,
+	}
+
+	// Files are assigned to modules as they are walked, so the manifests
+	// that define the modules are gathered by a first, quick walk.
+	var findings collector
+	manifests := newManifestSet(&findings, opts.Redact)
+	err = walker.Walk(ctx, absRoot, skipDirs, walkOpts, func(node *model.Node) error {
+		manifests.add(node)
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("failed to walk file tree: %w", err)
+	}
+	modules := newModuleSet(absRoot, manifests.list())
+	var selected map[string]bool
+	if opts.Module != "" {
+		if selected, err = modules.selectModule(opts.Module); err != nil {
+			return nil, err
+		}
+	}
+	history, err := loadHistory(ctx, absRoot, opts, &findings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := out.Begin(absRoot); err != nil {
+		return nil, err
+	}
+
+	includeSet := make(map[string]bool)
+	for _, ext := range includeExts {
+		includeSet[strings.TrimSpace(ext)] = true
+	}
+
+	base := newChangeBase(absRoot, opts)
+	store := openCache(absRoot, opts, base)
+	workers := runtime.NumCPU()
+	// The ordered queue bounds how far the walk may run ahead of the writer.
+	jobs := make(chan *pendingFile, workers)
+	ordered := make(chan *pendingFile, 4*workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				processNode(ctx, file.node, opts, base, nil, store, &findings)
+				close(file.done)
+			}
+		}()
+	}
+
+	filesScanned := 0
+	var stopped sync.Once
+	stop := make(chan struct{})
+	walkErr := make(chan error, 1)
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		walkErr <- walker.Walk(ctx, absRoot, skipDirs, walkOpts, func(node *model.Node) error {
+			node.Module = modules.of(node.Path)
+			if selected != nil && !selected[node.Module] {
+				return nil
+			}
+			filesScanned++
+			history.annotate(node)
+			if len(includeSet) > 0 && !includeSet[filepath.Ext(node.Path)] {
+				return nil
+			}
+			file := &pendingFile{node: node, done: make(chan struct{})}
+			select {
+			case ordered <- file:
+			case <-stop:
+				return errStreamStopped
+			}
+			jobs <- file
+			return nil
+		})
+	}()
+
+	stats := model.Analytics{PerLanguageStats: make(map[string]model.LanguageStats)}
+	ranking := newComplexityRanking(opts)
+	duplicates := newDuplicateIndex(opts, maxStreamedFunctions)
+	tests := newTestIndex(absRoot)
+	entries := newEntryIndex(manifests.list())
+	endpoints := newAPISurface()
+	debt := newTechDebt()
+	var changes *model.ChangeSummary
+	if base != nil {
+		changes = &model.ChangeSummary{Base: base.ref, Staged: base.staged}
+	}
+	var writeErr error
+	for file := range ordered {
+		<-file.done
+		if writeErr != nil {
+			continue
+		}
+		addFileStats(&stats, file.node)
+		ranking.add(file.node)
+		duplicates.add(file.node)
+		tests.add(file.node)
+		entries.add(file.node)
+		endpoints.add(file.node)
+		debt.add(file.node)
+		history.add(file.node)
+		if changes != nil {
+			addChange(changes, file.node)
+		}
+		if opts.HideSkipped && file.node.Skipped != "" {
+			continue
+		}
+		if writeErr = out.WriteFile(file.node); writeErr != nil {
+			stopped.Do(func() { close(stop) })
+		}
+	}
+	wg.Wait()
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	if err := <-walkErr; err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("failed to walk file tree: %w", err)
+	}
+	if store != nil && ctx.Err() == nil {
+		if err := store.Save(); err != nil {
+			findings.addDiagnostics(model.Diagnostic{Phase: PhaseCache, Kind: KindIO, Message: err.Error()})
+		}
+	}
+
+	if diagnostic, ok := duplicates.limitDiagnostic(); ok {
+		findings.addDiagnostics(diagnostic)
+	}
+
+	stats.FilesScanned = filesScanned
+	stats.Duration = time.Since(startTime)
+	stats.DurationReadable = stats.Duration.Round(time.Millisecond).String()
+	sortRedactions(findings.redactions)
+	sortDiagnostics(findings.diagnostics)
+	result := &model.AnalysisResult{
+		Root:         &model.Node{Name: filepath.Base(absRoot), Path: absRoot, IsDir: true},
+		Analytics:    stats,
+		Changes:      changes,
+		Redactions:   findings.redactions,
+		Diagnostics:  findings.diagnostics,
+		Complexity:   ranking.report(),
+		Duplicates:   duplicates.clusters(),
+		Tests:        tests.report(),
+		Dependencies: modules.manifests(manifests.list(), selected),
+		Modules:      modules.list(selected),
+		EntryPoints:  entries.list(),
+		APISurface:   endpoints.list(),
+		TechDebt:     debt.list(),
+		History:      history.result(),
+	}
+	markIncomplete(ctx, result)
+	return result, out.Finish(result)
+}
+
+// textStreamWriter renders a streamed analysis as text. Because later
+// siblings are not known yet when a file is written, the tree is drawn with
+// indentation instead of box-drawing connectors.
+type textStreamWriter struct {
+	w    *bufio.Writer
+	root string
+	// dirs holds the directories opened above the last written file.
+	dirs []string
+}
+
+// NewTextStreamWriter returns a StreamWriter that writes the text format to w.
+func NewTextStreamWriter(w io.Writer) StreamWriter {
+	return &textStreamWriter{w: bufio.NewWriter(w)}
+}
+
+func (t *textStreamWriter) Begin(root string) error {
+	t.root = root
+	fmt.Fprintf(t.w, "Codebase overview for: %s\n\n%s\n", root, root)
+	return t.w.Flush()
+}
+
+func (t *textStreamWriter) WriteFile(node *model.Node) error {
+	relPath := relativePath(t.root, node.Path)
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	dirs := parts[:len(parts)-1]
+
+	// Keep the directories shared with the previous file and open the rest.
+	common := 0
+	for common < len(dirs) && common < len(t.dirs) && dirs[common] == t.dirs[common] {
+		common++
+	}
+	for i := common; i < len(dirs); i++ {
+		fmt.Fprintf(t.w, "%s%s/\n", strings.Repeat("    ", i+1), dirs[i])
+	}
+	t.dirs = append(t.dirs[:0], dirs...)
+
+	indent := strings.Repeat("    ", len(dirs)+1)
+	fmt.Fprintf(t.w, "%s%s%s%s\n", indent, parts[len(parts)-1], statusLabel(node), skippedLabel(node))
+	sort.Slice(node.CodeElements, func(i, j int) bool {
+		return node.CodeElements[i].Line < node.CodeElements[j].Line
+	})
+	for _, el := range node.CodeElements {
+		fmt.Fprintf(t.w, "%s  %s\n", indent, formatElement(el))
+	}
+	// Every element of an added file is new, so only the element list is shown for it.
+	if node.Status != git.StatusAdded {
+		for _, change := range node.ElementChanges {
+			fmt.Fprintf(t.w, "%s  %s\n", indent, formatElementChange(change))
+		}
+	}
+	return t.w.Flush()
+}
+
+func (t *textStreamWriter) Finish(result *model.AnalysisResult) error {
+	// Landmarks are only known once every file was parsed, so a streamed
+	// overview lists them after the tree rather than before it.
+	if len(result.EntryPoints) > 0 {
+		var builder strings.Builder
+		builder.WriteString("\n")
+		appendEntryPoints(&builder, result.Root.Path, result.EntryPoints)
+		t.w.WriteString(builder.String())
+	}
+	t.w.WriteString(formatReport(result))
+	return t.w.Flush()
+}
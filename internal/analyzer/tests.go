@@ -0,0 +1,415 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// Element types of tests. Go and Python tests are found by name; the other
+// languages have queries for them.
+const (
+	ElementTest      = "Test"
+	ElementTestSuite = "Test Suite"
+)
+
+// testFunctionNames matches the functions that test frameworks run, per
+// language, in files named as tests.
+var testFunctionNames = map[string]*regexp.Regexp{
+	"Go":     regexp.MustCompile(`^(Test|Benchmark|Fuzz|Example)([A-Z_]|$)`),
+	"Python": regexp.MustCompile(`^test`),
+}
+
+// testLanguages are the languages whose tests are recognized.
+var testLanguages = map[string]bool{"Go": true, "Python": true, "JavaScript": true, "Java": true, "Rust": true}
+
+// testDirs are directory names that hold tests.
+var testDirs = map[string]bool{"test": true, "tests": true, "__tests__": true, "spec": true}
+
+// isTestFile reports whether a file is a test file by the naming conventions
+// of its language.
+func isTestFile(path, langName string) bool {
+	base := filepath.Base(path)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	switch langName {
+	case "Go":
+		return strings.HasSuffix(base, "_test.go")
+	case "Python":
+		return strings.HasPrefix(stem, "test_") || strings.HasSuffix(stem, "_test")
+	case "JavaScript":
+		return strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") || inTestDir(path)
+	case "Java":
+		return strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests") || strings.HasSuffix(stem, "IT") ||
+			strings.HasPrefix(stem, "Test") || strings.Contains(filepath.ToSlash(path), "/src/test/")
+	case "Rust":
+		return inTestDir(path)
+	}
+	return false
+}
+
+// inTestDir reports whether any directory above path is a test directory.
+func inTestDir(path string) bool {
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if testDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// testStem returns the name of the source file a test file is named after,
+// without extension: foo_test.go, test_foo.py, foo.test.js and FooTest.java
+// all give foo (or Foo).
+func testStem(path, langName string) string {
+	base := filepath.Base(path)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	switch langName {
+	case "Go", "Python":
+		stem = strings.TrimSuffix(strings.TrimPrefix(stem, "test_"), "_test")
+	case "JavaScript":
+		stem = strings.TrimSuffix(strings.TrimSuffix(stem, ".test"), ".spec")
+	case "Java":
+		for _, suffix := range []string{"Tests", "Test", "IT"} {
+			if trimmed := strings.TrimSuffix(stem, suffix); trimmed != stem && trimmed != "" {
+				return trimmed
+			}
+		}
+		stem = strings.TrimPrefix(stem, "Test")
+	}
+	return stem
+}
+
+// tagTests marks the test functions of a file node that are found by name
+// as tests.
+func tagTests(node *model.Node, lang model.Language) {
+	pattern, ok := testFunctionNames[lang.Name]
+	if !ok || !isTestFile(node.Path, lang.Name) {
+		return
+	}
+	for i := range node.CodeElements {
+		el := &node.CodeElements[i]
+		if el.Type == "Function" && pattern.MatchString(el.Name) {
+			el.Type = ElementTest
+		}
+	}
+}
+
+// countTests returns how many test functions a file node holds.
+func countTests(node *model.Node) int {
+	count := 0
+	for _, el := range node.CodeElements {
+		if el.Type == ElementTest {
+			count++
+		}
+	}
+	return count
+}
+
+// testedFile is what the test index remembers of an analyzed file.
+type testedFile struct {
+	path    string
+	lang    string
+	test    bool
+	tests   int
+	imports []string
+	// testable is set for source files with code elements, the ones that
+	// are expected to have tests.
+	testable bool
+}
+
+// testIndex collects the files of an analysis to map tests to sources.
+type testIndex struct {
+	root  string
+	files []testedFile
+}
+
+// newTestIndex returns an index for the tree at root.
+func newTestIndex(root string) *testIndex {
+	return &testIndex{root: root}
+}
+
+// add records a file node.
+func (t *testIndex) add(node *model.Node) {
+	lang, supported := GetLanguageByFileExtension(node.Path)
+	if !supported || node.Skipped != "" || node.Status == git.StatusDeleted {
+		return
+	}
+	if !testLanguages[lang.Name] {
+		return
+	}
+	t.files = append(t.files, testedFile{
+		path:     node.Path,
+		lang:     lang.Name,
+		test:     isTestFile(node.Path, lang.Name),
+		tests:    countTests(node),
+		imports:  node.Imports,
+		testable: len(node.CodeElements) > 0,
+	})
+}
+
+// report maps every test file to the source files it exercises and lists
+// the source files no test exercises. It returns nil when there are no tests.
+func (t *testIndex) report() *model.TestReport {
+	sources := make(map[string]*testedFile)
+	byStem := make(map[string][]*testedFile)
+	byDir := make(map[string][]*testedFile)
+	hasTests := false
+	for i := range t.files {
+		f := &t.files[i]
+		hasTests = hasTests || f.test || f.tests > 0
+		if f.test {
+			continue
+		}
+		sources[f.path] = f
+		base := filepath.Base(f.path)
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		byStem[stem] = append(byStem[stem], f)
+		byDir[filepath.Dir(f.path)] = append(byDir[filepath.Dir(f.path)], f)
+	}
+	if !hasTests {
+		return nil
+	}
+
+	report := &model.TestReport{}
+	tested := make(map[string]bool)
+	for _, f := range t.files {
+		if !f.test {
+			// Tests inside a source file, such as Rust's, exercise that file.
+			if f.tests > 0 {
+				tested[f.path] = true
+			}
+			continue
+		}
+		targets := make(map[string]bool)
+		for _, source := range t.namedSources(f, byStem[testStem(f.path, f.lang)]) {
+			targets[source.path] = true
+		}
+		if f.lang == "Go" {
+			// A Go test exercises its whole package.
+			for _, source := range byDir[filepath.Dir(f.path)] {
+				if source.lang == "Go" {
+					targets[source.path] = true
+				}
+			}
+		}
+		for _, imp := range f.imports {
+			for _, source := range t.resolveImport(f, imp, sources, byStem, byDir) {
+				targets[source.path] = true
+			}
+		}
+		mapping := model.TestMapping{Test: f.path, Tests: f.tests}
+		for path := range targets {
+			mapping.Sources = append(mapping.Sources, path)
+			tested[path] = true
+		}
+		sort.Strings(mapping.Sources)
+		report.Mappings = append(report.Mappings, mapping)
+	}
+	for _, f := range t.files {
+		if f.test {
+			continue
+		}
+		if f.testable {
+			report.SourceFiles++
+			if !tested[f.path] {
+				report.Untested = append(report.Untested, f.path)
+			}
+		}
+	}
+	sort.Slice(report.Mappings, func(i, j int) bool { return report.Mappings[i].Test < report.Mappings[j].Test })
+	sort.Strings(report.Untested)
+	return report
+}
+
+// namedSources picks, among the source files named like a test file, the
+// ones closest to it: in the same directory, a parent directory or a
+// mirrored tree such as src/main/java for src/test/java.
+func (t *testIndex) namedSources(test testedFile, candidates []*testedFile) []*testedFile {
+	testDir := t.dirParts(test.path)
+	best, bestScore := []*testedFile(nil), -1
+	for _, c := range candidates {
+		if c.lang != test.lang {
+			continue
+		}
+		dir := t.dirParts(c.path)
+		score := commonPrefix(testDir, dir)
+		if suffix := commonSuffix(testDir, dir); suffix > score {
+			score = suffix
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore = []*testedFile{c}, score
+		case score == bestScore:
+			best = append(best, c)
+		}
+	}
+	// Unrelated files that merely share a name are not a match.
+	if bestScore == 0 && len(best) > 1 {
+		return nil
+	}
+	return best
+}
+
+// resolveImport returns the source files of the tree that an import of a
+// test file refers to: a relative path, or a module or package path whose
+// end names a file or directory of the tree.
+func (t *testIndex) resolveImport(test testedFile, imp string, sources map[string]*testedFile, byStem, byDir map[string][]*testedFile) []*testedFile {
+	var found []*testedFile
+	sameLanguage := func(files []*testedFile) []*testedFile {
+		var matched []*testedFile
+		for _, f := range files {
+			if f.lang == test.lang {
+				matched = append(matched, f)
+			}
+		}
+		return matched
+	}
+
+	if strings.HasPrefix(imp, ".") && test.lang != "Python" {
+		target := filepath.Join(filepath.Dir(test.path), filepath.FromSlash(imp))
+		if f, ok := sources[target]; ok {
+			return []*testedFile{f}
+		}
+		for _, f := range byDir[filepath.Dir(target)] {
+			base := filepath.Base(f.path)
+			if strings.TrimSuffix(base, filepath.Ext(base)) == filepath.Base(target) {
+				found = append(found, f)
+			}
+		}
+		for _, f := range byDir[target] {
+			if base := filepath.Base(f.path); strings.TrimSuffix(base, filepath.Ext(base)) == "index" {
+				found = append(found, f)
+			}
+		}
+		return sameLanguage(found)
+	}
+
+	modulePath := imp
+	switch test.lang {
+	case "Python":
+		dots := len(imp) - len(strings.TrimLeft(imp, "."))
+		modulePath = strings.ReplaceAll(imp[dots:], ".", "/")
+		if dots > 0 {
+			dir := filepath.Dir(test.path)
+			for i := 1; i < dots; i++ {
+				dir = filepath.Dir(dir)
+			}
+			rel, err := filepath.Rel(t.root, dir)
+			if err != nil {
+				return nil
+			}
+			modulePath = strings.TrimPrefix(filepath.ToSlash(filepath.Join(rel, modulePath)), "./")
+		}
+	case "Java":
+		modulePath = strings.ReplaceAll(imp, ".", "/")
+	case "Rust":
+		return nil
+	}
+	if modulePath == "" || modulePath == "." {
+		return nil
+	}
+
+	// A file whose path ends with the module path, e.g. pkg/mod.py for
+	// pkg.mod, or the package's __init__.py.
+	parts := strings.Split(modulePath, "/")
+	for _, stem := range []string{parts[len(parts)-1], "__init__"} {
+		for _, f := range byStem[stem] {
+			relPath := t.relNoExt(f.path)
+			if stem == "__init__" {
+				relPath = strings.TrimSuffix(relPath, "/__init__")
+			}
+			if pathHasSuffix(modulePath, relPath) || (stem != "__init__" && pathHasSuffix(relPath, modulePath)) {
+				found = append(found, f)
+			}
+		}
+	}
+	if len(found) > 0 {
+		return sameLanguage(found)
+	}
+	// Otherwise a directory, e.g. a Go package or a Java package import.
+	for dir, files := range byDir {
+		relDir, err := filepath.Rel(t.root, dir)
+		if err != nil || relDir == "." {
+			continue
+		}
+		if pathHasSuffix(modulePath, filepath.ToSlash(relDir)) {
+			found = append(found, files...)
+		}
+	}
+	return sameLanguage(found)
+}
+
+// relNoExt returns path relative to the root, slash-separated and without
+// its extension.
+func (t *testIndex) relNoExt(path string) string {
+	relPath := relativePath(t.root, path)
+	return strings.TrimSuffix(filepath.ToSlash(relPath), filepath.Ext(relPath))
+}
+
+// dirParts splits the directory of path, relative to the root.
+func (t *testIndex) dirParts(path string) []string {
+	relDir, err := filepath.Rel(t.root, filepath.Dir(path))
+	if err != nil || relDir == "." {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(relDir), "/")
+}
+
+// pathHasSuffix reports whether the slash-separated path ends with the whole
+// components of suffix.
+func pathHasSuffix(path, suffix string) bool {
+	return path == suffix || strings.HasSuffix(path, "/"+suffix)
+}
+
+// commonPrefix counts the leading components a and b share.
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// commonSuffix counts the trailing components a and b share.
+func commonSuffix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// appendTests summarizes the tests of the tree and the source files they
+// exercise.
+func appendTests(builder *strings.Builder, rootPath string, stats model.Analytics, report *model.TestReport) {
+	rel := func(path string) string {
+		return relativePath(rootPath, path)
+	}
+	builder.WriteString("🧪 Tests\n")
+	builder.WriteString("────────────────────────────────────────\n")
+	builder.WriteString(fmt.Sprintf("%d test file(s), %d test(s); %d of %d source file(s) are exercised by a test.\n",
+		stats.TestFiles, stats.Tests, report.SourceFiles-len(report.Untested), report.SourceFiles))
+	for _, mapping := range report.Mappings {
+		sources := make([]string, len(mapping.Sources))
+		for i, source := range mapping.Sources {
+			sources[i] = rel(source)
+		}
+		target := "(no source file found)"
+		if len(sources) > 0 {
+			target = strings.Join(sources, ", ")
+		}
+		builder.WriteString(fmt.Sprintf("  - %s (%d) → %s\n", rel(mapping.Test), mapping.Tests, target))
+	}
+	if len(report.Untested) > 0 {
+		builder.WriteString("Untested source files:\n")
+		for _, path := range report.Untested {
+			builder.WriteString(fmt.Sprintf("  - %s\n", rel(path)))
+		}
+	}
+	builder.WriteString("\n")
+}
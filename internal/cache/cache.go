@@ -0,0 +1,175 @@
+// Package cache persists per-file parse results between runs, so files that
+// have not changed are neither read nor parsed again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// FormatVersion identifies the layout of Entry. Bump it whenever Entry
+// changes, so caches written by older builds are discarded rather than
+// decoded into the wrong fields.
+const FormatVersion = 1
+
+// Entry holds the parse results of one file and what they were derived from.
+type Entry struct {
+	Size    int64
+	ModTime int64
+	// Hash is the SHA-256 of the content, used when size or mtime change
+	// without the content changing, e.g. after a checkout.
+	Hash        string
+	Lines       model.LineCounts
+	Elements    []model.CodeElement
+	Imports     []string
+	Routes      []model.Route
+	Annotations []model.Annotation
+	// Skeleton is only meaningful when HasSkeleton is set.
+	Skeleton    string
+	HasSkeleton bool
+	// SyntaxErrors keeps the parse problems so they are reported on every run.
+	SyntaxErrors []model.SyntaxError
+	// Skipped records why the file was not parsed, if it was not.
+	Skipped string
+}
+
+// file is the on-disk representation of a cache.
+type file struct {
+	Fingerprint string
+	Entries     map[string]*Entry
+}
+
+// Cache maps absolute file paths to parse results. It is safe for
+// concurrent use.
+type Cache struct {
+	path        string
+	fingerprint string
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+	dirty   bool
+}
+
+// DefaultDir returns the directory groot keeps its caches in.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "groot"), nil
+}
+
+// Open loads the cache of the tree at root from dir. fingerprint identifies
+// everything besides file content that parse results depend on, such as the
+// groot version and its queries; a cache written with another fingerprint is
+// discarded. A missing or unreadable cache yields an empty one.
+func Open(dir, root, fingerprint string) *Cache {
+	sum := sha256.Sum256([]byte(root))
+	c := &Cache{
+		path:        filepath.Join(dir, hex.EncodeToString(sum[:8])+".gob"),
+		fingerprint: fingerprint,
+		entries:     make(map[string]*Entry),
+	}
+	f, err := os.Open(c.path)
+	if err != nil {
+		return c
+	}
+	defer f.Close()
+	var stored file
+	if err := gob.NewDecoder(f).Decode(&stored); err != nil || stored.Fingerprint != fingerprint {
+		c.dirty = true
+		return c
+	}
+	if stored.Entries != nil {
+		c.entries = stored.Entries
+	}
+	return c
+}
+
+// Lookup returns the entry for path when the file's size and mtime are
+// unchanged. skeleton requires the entry to hold a skeleton.
+func (c *Cache) Lookup(path string, info os.FileInfo, skeleton bool) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || entry.Size != info.Size() || entry.ModTime != info.ModTime().UnixNano() || (skeleton && !entry.HasSkeleton) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// LookupContent returns the entry for path when its content hash matches,
+// and records the file's new size and mtime so the next Lookup hits.
+func (c *Cache) LookupContent(path string, info os.FileInfo, hash string, skeleton bool) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || entry.Hash != hash || (skeleton && !entry.HasSkeleton) {
+		return nil, false
+	}
+	entry.Size, entry.ModTime = info.Size(), info.ModTime().UnixNano()
+	c.dirty = true
+	return entry, true
+}
+
+// Store records the parse results of path. The entry must not be modified
+// afterwards.
+func (c *Cache) Store(path string, info os.FileInfo, entry *Entry) {
+	entry.Size, entry.ModTime = info.Size(), info.ModTime().UnixNano()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = entry
+	c.dirty = true
+}
+
+// Save writes the cache back to disk, if anything changed. Entries of files
+// this run did not look at are kept, since runs restricted with --include,
+// --since or --module see only part of the tree; entries of files that no
+// longer exist are dropped.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.entries {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			delete(c.entries, path)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("could not create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".groot-cache-*")
+	if err != nil {
+		return fmt.Errorf("could not write cache: %w", err)
+	}
+	encodeErr := gob.NewEncoder(tmp).Encode(file{Fingerprint: c.fingerprint, Entries: c.entries})
+	closeErr := tmp.Close()
+	if encodeErr == nil {
+		encodeErr = closeErr
+	}
+	if encodeErr != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write cache: %w", encodeErr)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write cache: %w", err)
+	}
+	return nil
+}
+
+// Hash returns the content hash stored in entries.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveKeepsUnusedEntriesAndPrunesDeletedFiles(t *testing.T) {
+	dir, root := t.TempDir(), t.TempDir()
+	paths := make(map[string]string)
+	for _, name := range []string{"kept.go", "untouched.go", "deleted.go"} {
+		paths[name] = filepath.Join(root, name)
+		if err := os.WriteFile(paths[name], []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := Open(dir, root, "v1")
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Store(path, info, &Entry{Imports: []string{"fmt"}})
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second run only looks at one file, and another one is deleted.
+	if err := os.Remove(paths["deleted.go"]); err != nil {
+		t.Fatal(err)
+	}
+	c = Open(dir, root, "v1")
+	info, err := os.Stat(paths["kept.go"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Lookup(paths["kept.go"], info, false); !ok {
+		t.Fatal("Lookup missed an unchanged file")
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = Open(dir, root, "v1")
+	for name, want := range map[string]bool{"kept.go": true, "untouched.go": true, "deleted.go": false} {
+		if _, ok := c.entries[paths[name]]; ok != want {
+			t.Errorf("entry for %s present = %v, want %v", name, ok, want)
+		}
+	}
+
+	// A different fingerprint discards everything.
+	if c = Open(dir, root, "v2"); len(c.entries) != 0 {
+		t.Errorf("Open with a new fingerprint kept %d entries", len(c.entries))
+	}
+}
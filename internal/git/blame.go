@@ -0,0 +1,93 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine tells who last changed a line, and when.
+type BlameLine struct {
+	Author string
+	Time   time.Time
+}
+
+// uncommitted is the commit git blame reports for lines changed in the
+// working tree.
+const uncommitted = "0000000000000000000000000000000000000000"
+
+// Blame returns who last changed each of the given 1-based lines of the
+// file at path. Lines that are not committed, and every line of an
+// untracked file, are left out.
+func Blame(ctx context.Context, path string, lines []int) (map[int]BlameLine, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	tracked, err := Run(ctx, "-C", dir, "ls-files", "-z", "--", name)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracked) == 0 {
+		return nil, nil
+	}
+
+	lines = append([]int(nil), lines...)
+	sort.Ints(lines)
+	args := []string{"-C", dir, "blame", "--line-porcelain"}
+	for i, line := range lines {
+		if i == 0 || line != lines[i-1] {
+			args = append(args, "-L", fmt.Sprintf("%d,%d", line, line))
+		}
+	}
+	out, err := Run(ctx, append(args, "--", name)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(string(out))
+}
+
+// parseBlame decodes the output of `git blame --line-porcelain`: for every
+// line, a header with the commit and line numbers, then key-value fields,
+// then the content prefixed by a tab.
+func parseBlame(out string) (map[int]BlameLine, error) {
+	blame := make(map[int]BlameLine)
+	var commit string
+	var line int
+	var current BlameLine
+	header := true
+	for _, text := range strings.Split(out, "\n") {
+		switch {
+		case text == "" && header:
+			continue
+		case header:
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("malformed git blame output near %q", text)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("malformed git blame output near %q", text)
+			}
+			commit, line, current, header = fields[0], n, BlameLine{}, false
+		case strings.HasPrefix(text, "\t"):
+			if commit != uncommitted {
+				blame[line] = current
+			}
+			header = true
+		case strings.HasPrefix(text, "author "):
+			current.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-time "):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed git blame output near %q", text)
+			}
+			current.Time = time.Unix(seconds, 0).UTC()
+		}
+	}
+	return blame, nil
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// porcelainLine returns the --line-porcelain record of one line.
+func porcelainLine(commit string, line int, author string, unix int64, content string) string {
+	return commit + " " + strconv.Itoa(line) + " " + strconv.Itoa(line) + " 1\n" +
+		"author " + author + "\nauthor-mail <a@example.com>\nauthor-time " + strconv.Itoa(int(unix)) + "\nauthor-tz +0000\n" +
+		"committer " + author + "\ncommitter-mail <a@example.com>\ncommitter-time " + strconv.Itoa(int(unix)) + "\ncommitter-tz +0000\n" +
+		"summary two\nprevious 864e860bc87f61c5ac23b62a2c816499c61b1bb5 src/a.go\nfilename src/b.go\n\t" + content + "\n"
+}
+
+func TestParseBlame(t *testing.T) {
+	const commit = "053ed16e1949cdc255c7cd6e4ffadc5e2f6d206a"
+	tests := []struct {
+		name    string
+		out     string
+		want    map[int]BlameLine
+		wantErr bool
+	}{
+		{
+			name: "committed lines",
+			out:  porcelainLine(commit, 2, "Bob", 1700086400, "B") + porcelainLine(commit, 4, "Ana Díaz", 1700000000, "// TODO: author lines look like headers"),
+			want: map[int]BlameLine{
+				2: {Author: "Bob", Time: time.Unix(1700086400, 0).UTC()},
+				4: {Author: "Ana Díaz", Time: time.Unix(1700000000, 0).UTC()},
+			},
+		},
+		{
+			name: "uncommitted lines are left out",
+			out:  porcelainLine(uncommitted, 6, "Not Committed Yet", 1792434125, "z") + porcelainLine(commit, 7, "Bob", 1700086400, "\tindented"),
+			want: map[int]BlameLine{7: {Author: "Bob", Time: time.Unix(1700086400, 0).UTC()}},
+		},
+		{name: "no lines", out: "", want: map[int]BlameLine{}},
+		{name: "header without line numbers", out: commit + "\n", wantErr: true},
+		{name: "bad author time", out: commit + " 1 1 1\nauthor Bob\nauthor-time soon\n\tx\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBlame(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("blame = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
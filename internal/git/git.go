@@ -0,0 +1,304 @@
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo describes a local git repository discovered on disk.
+type Repo struct {
+	// Root is the absolute path of the working tree.
+	Root string
+	// GitDir is the repository's private directory, usually Root/.git.
+	GitDir string
+	// CommonDir holds data shared between worktrees, such as info/exclude.
+	CommonDir string
+}
+
+// FindRepo walks up from path until it finds a directory containing a .git
+// directory or file. It returns nil when path is not inside a repository.
+func FindRepo(path string) (*Repo, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", path, err)
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				// Worktrees and submodules use a .git file pointing at the real directory.
+				gitDir, err = readGitFile(dotGit)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return &Repo{Root: dir, GitDir: gitDir, CommonDir: commonDir(gitDir)}, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// readGitFile resolves the "gitdir: <path>" indirection of a .git file.
+func readGitFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %w", path, err)
+	}
+	line := strings.TrimSpace(string(content))
+	gitDir, ok := strings.CutPrefix(line, "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("malformed git file %s", path)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// commonDir returns the directory shared by all worktrees of gitDir.
+func commonDir(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// InfoExcludePath returns the location of the repository's info/exclude file.
+func (r *Repo) InfoExcludePath() string {
+	return filepath.Join(r.CommonDir, "info", "exclude")
+}
+
+// TrackedFiles lists the files in the index below dir, as slash-separated
+// paths relative to dir.
+func TrackedFiles(ctx context.Context, dir string) ([]string, error) {
+	out, err := Run(ctx, "-C", dir, "ls-files", "-z", "--cached")
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(out), nil
+}
+
+// Change statuses reported by ChangedFiles.
+const (
+	StatusAdded    = "added"
+	StatusModified = "modified"
+	StatusDeleted  = "deleted"
+	StatusRenamed  = "renamed"
+)
+
+// Change is a file that differs between a ref and the working tree or index.
+type Change struct {
+	Status string
+	// Path is slash-separated and relative to the directory that was diffed.
+	Path string
+	// OldPath is set for renames and holds the path at the ref.
+	OldPath string
+}
+
+// ChangedFiles lists the files below dir that differ from ref. With staged
+// set the index is compared instead of the working tree, and an empty ref
+// means HEAD. Untracked files count as added when comparing the working tree.
+func ChangedFiles(ctx context.Context, dir, ref string, staged bool) ([]Change, error) {
+	args := []string{"-C", dir, "diff", "--name-status", "-z", "-M", "--relative"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if ref != "" {
+		args = append(args, ref)
+	}
+	out, err := Run(ctx, append(args, "--")...)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := parseNameStatus(splitNul(out))
+	if err != nil {
+		return nil, err
+	}
+
+	if !staged {
+		out, err := Run(ctx, "-C", dir, "ls-files", "-z", "--others", "--exclude-standard")
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range splitNul(out) {
+			changes = append(changes, Change{Status: StatusAdded, Path: path})
+		}
+	}
+	return changes, nil
+}
+
+// parseNameStatus decodes the records of `git diff --name-status -z`.
+func parseNameStatus(records []string) ([]Change, error) {
+	var changes []Change
+	for i := 0; i < len(records); i++ {
+		code := records[i]
+		if code == "" || i+1 >= len(records) {
+			return nil, fmt.Errorf("malformed git diff output near %q", code)
+		}
+		switch code[0] {
+		case 'R', 'C':
+			if i+2 >= len(records) {
+				return nil, fmt.Errorf("malformed git diff output near %q", code)
+			}
+			change := Change{Status: StatusRenamed, OldPath: records[i+1], Path: records[i+2]}
+			if code[0] == 'C' {
+				// A copy leaves the original in place, so the new path is simply added.
+				change = Change{Status: StatusAdded, Path: records[i+2]}
+			}
+			changes = append(changes, change)
+			i += 2
+			continue
+		case 'A':
+			changes = append(changes, Change{Status: StatusAdded, Path: records[i+1]})
+		case 'D':
+			changes = append(changes, Change{Status: StatusDeleted, Path: records[i+1]})
+		default:
+			changes = append(changes, Change{Status: StatusModified, Path: records[i+1]})
+		}
+		i++
+	}
+	return changes, nil
+}
+
+// ShowFile returns the content of relPath, relative to dir, at ref. An empty
+// ref reads the version staged in the index.
+func ShowFile(ctx context.Context, dir, ref, relPath string) ([]byte, error) {
+	return Run(ctx, "-C", dir, "show", ref+":./"+relPath)
+}
+
+// ExportTree writes the files below dir as they are at ref into dest, which
+// must exist. It reads the repository directly and needs no network access.
+func ExportTree(ctx context.Context, dir, ref, dest string) error {
+	prefix, err := Run(ctx, "-C", dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "archive", "--format=tar", ref+":"+strings.TrimSpace(string(prefix)))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git archive: %w", err)
+	}
+	extractErr := extractTar(stdout, dest)
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git archive %s: %s", ref, msg)
+		}
+		return fmt.Errorf("git archive %s: %w", ref, err)
+	}
+	return extractErr
+}
+
+// extractTar unpacks regular files and directories from a tar stream into dest.
+func extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read archive: %w", err)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(filepath.Separator)) {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, copyErr := io.Copy(file, tr)
+			closeErr := file.Close()
+			if copyErr != nil {
+				return copyErr
+			}
+			if closeErr != nil {
+				return closeErr
+			}
+		}
+	}
+}
+
+// splitNul splits the NUL-terminated records printed by git's -z option.
+func splitNul(out []byte) []string {
+	var records []string
+	for _, record := range strings.Split(string(out), "\x00") {
+		if record != "" {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// ExcludesFile returns the path of the global ignore file configured through
+// core.excludesFile, falling back to git's default location. dir is used to
+// pick up repository-local configuration and may be empty.
+func ExcludesFile(ctx context.Context, dir string) string {
+	args := []string{"config", "--path", "--get", "core.excludesFile"}
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	if out, err := Run(ctx, args...); err == nil {
+		if path := strings.TrimSpace(string(out)); path != "" {
+			return path
+		}
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "git", "ignore")
+}
+
+// Run executes the git binary with args and returns its standard output.
+// The process is killed when ctx is done.
+func Run(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
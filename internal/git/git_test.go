@@ -0,0 +1,119 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunStopsWhenContextIsDone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, "--version"); err == nil {
+		t.Error("Run succeeded with a cancelled context, want an error")
+	}
+	if _, err := Run(context.Background(), "--version"); err != nil {
+		t.Errorf("Run: %v", err)
+	}
+}
+
+func TestSplitNul(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"terminated records", "a.go\x00dir/b c.go\x00", []string{"a.go", "dir/b c.go"}},
+		{"unquoted unicode and newlines", "Díaz.go\x00line\nbreak.go\x00", []string{"Díaz.go", "line\nbreak.go"}},
+		{"unterminated last record", "a.go\x00b.go", []string{"a.go", "b.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitNul([]byte(tt.out)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitNul(%q) = %q, want %q", tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	for _, name := range []string{"main.go", "sub/sp ace.go", "sub/Díaz.go", "untracked.go"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := exec.Command("git", "-C", root, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if out, err := exec.Command("git", "-C", root, "add", "main.go", "sub").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+
+	files, err := TrackedFiles(context.Background(), filepath.Join(root, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Díaz.go", "sp ace.go"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("TrackedFiles = %q, want %q", files, want)
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    []Change
+		wantErr bool
+	}{
+		{
+			name: "added, modified, deleted and type-changed",
+			out:  "M\x00bin.dat\x00A\x00n.go\x00D\x00sp ace.txt\x00T\x00link\x00",
+			want: []Change{
+				{Status: StatusModified, Path: "bin.dat"},
+				{Status: StatusAdded, Path: "n.go"},
+				{Status: StatusDeleted, Path: "sp ace.txt"},
+				{Status: StatusModified, Path: "link"},
+			},
+		},
+		{
+			name: "renames and copies carry a score and two paths",
+			out:  "R100\x00n.go\x00m.go\x00C075\x00a.go\x00a_copy.go\x00M\x00x.go\x00",
+			want: []Change{
+				{Status: StatusRenamed, OldPath: "n.go", Path: "m.go"},
+				{Status: StatusAdded, Path: "a_copy.go"},
+				{Status: StatusModified, Path: "x.go"},
+			},
+		},
+		{name: "no changes", out: "", want: nil},
+		{name: "status without a path", out: "M\x00", wantErr: true},
+		{name: "rename without its new path", out: "R090\x00old.go\x00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNameStatus(splitNul([]byte(tt.out)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
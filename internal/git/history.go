@@ -0,0 +1,99 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileChurn is how a file changed over a window of history.
+type FileChurn struct {
+	Commits    int
+	LastCommit time.Time
+	// Authors maps each author to the number of their commits.
+	Authors map[string]int
+	Added   int
+	Deleted int
+}
+
+// History reads the commits below dir made since the given time, or all of
+// them when it is zero, merges left out. It returns how each file changed,
+// keyed by slash-separated path relative to dir, and the number of commits.
+// Renames are not followed: a renamed file starts a new history.
+func History(ctx context.Context, dir string, since time.Time) (map[string]*FileChurn, int, error) {
+	// Paths with non-ASCII characters are printed as they are, not quoted.
+	args := []string{"-c", "core.quotePath=false", "-C", dir, "log", "--no-merges", "--no-renames", "--relative", "--numstat", "--format=%x00%at%x09%aN"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	out, err := Run(ctx, append(args, "--", ".")...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseHistory(string(out))
+}
+
+// parseHistory decodes the output of History's git log: a NUL-prefixed
+// header with the time and author of each commit, then a line of added
+// lines, deleted lines and path for each file it changed. Binary files
+// count "-" lines, and renames, if git reports them, count for the new path.
+func parseHistory(out string) (map[string]*FileChurn, int, error) {
+	files := make(map[string]*FileChurn)
+	commits := 0
+	var author string
+	var when time.Time
+	for _, line := range strings.Split(out, "\n") {
+		if header, ok := strings.CutPrefix(line, "\x00"); ok {
+			seconds, name, found := strings.Cut(header, "\t")
+			unix, err := strconv.ParseInt(seconds, 10, 64)
+			if !found || err != nil {
+				return nil, 0, fmt.Errorf("malformed git log output near %q", header)
+			}
+			author, when = name, time.Unix(unix, 0).UTC()
+			commits++
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return nil, 0, fmt.Errorf("malformed git log output near %q", line)
+		}
+		path := renamedPath(fields[2])
+		churn, ok := files[path]
+		if !ok {
+			churn = &FileChurn{Authors: make(map[string]int)}
+			files[path] = churn
+		}
+		churn.Commits++
+		churn.Authors[author]++
+		if when.After(churn.LastCommit) {
+			churn.LastCommit = when
+		}
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		churn.Added += added
+		churn.Deleted += deleted
+	}
+	return files, commits, nil
+}
+
+// renamedPath returns the new path of a numstat entry that git writes as a
+// rename, such as src/{a.go => b.go} or old.go => new.go, and other paths
+// unchanged.
+func renamedPath(path string) string {
+	if open := strings.Index(path, "{"); open >= 0 {
+		if end := strings.Index(path[open:], "}"); end > 0 {
+			if _, to, ok := strings.Cut(path[open+1:open+end], " => "); ok {
+				return strings.ReplaceAll(path[:open]+to+path[open+end+1:], "//", "/")
+			}
+		}
+	}
+	if _, to, ok := strings.Cut(path, " => "); ok {
+		return to
+	}
+	return path
+}
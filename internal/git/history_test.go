@@ -0,0 +1,69 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHistory(t *testing.T) {
+	day1, day2 := time.Unix(1700000000, 0).UTC(), time.Unix(1700086400, 0).UTC()
+	tests := []struct {
+		name    string
+		out     string
+		files   map[string]*FileChurn
+		commits int
+		wantErr bool
+	}{
+		{
+			name: "numstat with binary files",
+			out: "\x001700086400\tBob\n\n-\t-\tbin.dat\n2\t1\tsrc/b.go\n" +
+				"\x001700000000\tAna Díaz\n\n-\t-\tbin.dat\n1\t0\tsp ace.txt\n3\t0\tsrc/b.go\n",
+			files: map[string]*FileChurn{
+				"bin.dat":    {Commits: 2, LastCommit: day2, Authors: map[string]int{"Bob": 1, "Ana Díaz": 1}},
+				"src/b.go":   {Commits: 2, LastCommit: day2, Authors: map[string]int{"Bob": 1, "Ana Díaz": 1}, Added: 5, Deleted: 1},
+				"sp ace.txt": {Commits: 1, LastCommit: day1, Authors: map[string]int{"Ana Díaz": 1}, Added: 1},
+			},
+			commits: 2,
+		},
+		{
+			name: "renames count for the new path",
+			out: "\x001700086400\tBob\n\n2\t1\tsrc/{a.go => b.go}\n1\t1\told.go => new.go\n0\t0\tsrc/{ => sub}/c.go\n" +
+				"\x001700000000\tBob\n\n3\t0\tsrc/b.go\n",
+			files: map[string]*FileChurn{
+				"src/b.go":     {Commits: 2, LastCommit: day2, Authors: map[string]int{"Bob": 2}, Added: 5, Deleted: 1},
+				"new.go":       {Commits: 1, LastCommit: day2, Authors: map[string]int{"Bob": 1}, Added: 1, Deleted: 1},
+				"src/sub/c.go": {Commits: 1, LastCommit: day2, Authors: map[string]int{"Bob": 1}},
+			},
+			commits: 2,
+		},
+		{
+			name:    "commits without file changes",
+			out:     "\x001700000000\tBob\n",
+			files:   map[string]*FileChurn{},
+			commits: 1,
+		},
+		{name: "header without an author", out: "\x001700000000\n", wantErr: true},
+		{name: "truncated numstat line", out: "\x001700000000\tBob\n\n3\tsrc/b.go\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, commits, err := parseHistory(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if commits != tt.commits {
+				t.Errorf("commits = %d, want %d", commits, tt.commits)
+			}
+			if !reflect.DeepEqual(files, tt.files) {
+				for path, churn := range files {
+					t.Logf("%s: %+v", path, *churn)
+				}
+				t.Errorf("files differ from %d expected", len(tt.files))
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// Ecosystems of the manifests that are understood.
+const (
+	EcosystemGo    = "go"
+	EcosystemNpm   = "npm"
+	EcosystemPyPI  = "pypi"
+	EcosystemCargo = "cargo"
+	EcosystemMaven = "maven"
+)
+
+// IsManifest reports whether a file name is that of a dependency manifest.
+func IsManifest(name string) bool {
+	switch name {
+	case "go.mod", "package.json", "pyproject.toml", "Cargo.toml", "pom.xml", "build.gradle", "build.gradle.kts",
+		"go.work", "pnpm-workspace.yaml", "settings.gradle", "settings.gradle.kts":
+		return true
+	}
+	return isRequirements(name)
+}
+
+// isRequirements matches pip requirement files: requirements.txt and
+// variants such as requirements-dev.txt.
+func isRequirements(name string) bool {
+	return strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt")
+}
+
+// Parse reads the direct dependencies declared by the manifest file named
+// name. The result's Path is left for the caller to set.
+func Parse(name string, content []byte) (*model.Manifest, error) {
+	var m *model.Manifest
+	var err error
+	switch {
+	case name == "go.mod":
+		m = parseGoMod(content)
+	case name == "go.work":
+		m = parseGoWork(content)
+	case name == "pnpm-workspace.yaml":
+		m = parsePnpmWorkspace(content)
+	case name == "package.json":
+		m, err = parsePackageJSON(content)
+	case name == "pyproject.toml":
+		m = parsePyProject(content)
+	case name == "Cargo.toml":
+		m = parseCargo(content)
+	case name == "pom.xml":
+		m, err = parsePom(content)
+	case name == "build.gradle", name == "build.gradle.kts":
+		m = parseGradle(content)
+	case name == "settings.gradle", name == "settings.gradle.kts":
+		m = parseGradleSettings(content)
+	case isRequirements(name):
+		m = parseRequirements(name, content)
+	default:
+		return nil, fmt.Errorf("'%s' is not a known dependency manifest", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sortDependencies(m.Dependencies)
+	return m, nil
+}
+
+// sortDependencies orders runtime dependencies first, then each scope, by name.
+func sortDependencies(deps []model.Dependency) {
+	sort.SliceStable(deps, func(i, j int) bool {
+		if deps[i].Scope != deps[j].Scope {
+			return deps[i].Scope < deps[j].Scope
+		}
+		return deps[i].Name < deps[j].Name
+	})
+}
+
+// parseGoMod reads the module path and the direct requirements of a go.mod
+// file; requirements marked indirect are left out.
+func parseGoMod(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemGo}
+	require := func(fields []string) {
+		if len(fields) >= 2 {
+			m.Dependencies = append(m.Dependencies, model.Dependency{Name: strings.Trim(fields[0], `"`), Version: fields[1]})
+		}
+	}
+	block := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		indirect := strings.Contains(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+			} else if block == "require" && !indirect {
+				require(fields)
+			}
+			continue
+		}
+		if len(fields) >= 2 && fields[len(fields)-1] == "(" {
+			block = fields[0]
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			if len(fields) >= 2 {
+				m.Module = strings.Trim(fields[1], `"`)
+			}
+		case "require":
+			if !indirect {
+				require(fields[1:])
+			}
+		}
+	}
+	return m
+}
+
+// parseGoWork reads the modules a go.work file uses.
+func parseGoWork(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemGo}
+	inUse := false
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inUse:
+			if fields[0] == ")" {
+				inUse = false
+			} else {
+				m.Workspace = append(m.Workspace, strings.Trim(fields[0], `"`))
+			}
+		case fields[0] == "use" && len(fields) >= 2:
+			if fields[1] == "(" {
+				inUse = true
+			} else {
+				m.Workspace = append(m.Workspace, strings.Trim(fields[1], `"`))
+			}
+		}
+	}
+	return m
+}
+
+// packageJSON holds the fields of package.json that matter here.
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	// Workspaces is a list of patterns or, for Yarn, an object holding one.
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// parsePackageJSON reads the dependency groups of a package.json file.
+func parsePackageJSON(content []byte) (*model.Manifest, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+	m := &model.Manifest{Ecosystem: EcosystemNpm, Module: pkg.Name, Version: pkg.Version}
+	if len(pkg.Workspaces) > 0 {
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &m.Workspace); err != nil && json.Unmarshal(pkg.Workspaces, &yarn) == nil {
+			m.Workspace = yarn.Packages
+		}
+	}
+	for _, group := range []struct {
+		scope string
+		deps  map[string]string
+	}{
+		{"", pkg.Dependencies},
+		{"dev", pkg.DevDependencies},
+		{"peer", pkg.PeerDependencies},
+		{"optional", pkg.OptionalDependencies},
+	} {
+		for name, version := range group.deps {
+			m.Dependencies = append(m.Dependencies, model.Dependency{Name: name, Version: version, Scope: group.scope})
+		}
+	}
+	return m, nil
+}
+
+// parsePnpmWorkspace reads the package patterns of a pnpm-workspace.yaml
+// file: the items of its top-level packages list.
+func parsePnpmWorkspace(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemNpm}
+	inPackages := false
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-"):
+			inPackages = trimmed == "packages:"
+		case inPackages && strings.HasPrefix(trimmed, "-"):
+			pattern := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")), `"'`)
+			// Exclusions such as !**/test/** select nothing.
+			if !strings.HasPrefix(pattern, "!") {
+				m.Workspace = append(m.Workspace, pattern)
+			}
+		}
+	}
+	return m
+}
+
+// requirementPattern splits a PEP 508 requirement into its name, extras and
+// version specifier.
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// parseRequirement reads a PEP 508 requirement such as
+// "requests[socks]>=2.31; python_version > '3.8'".
+func parseRequirement(req string) (model.Dependency, bool) {
+	if i := strings.Index(req, ";"); i >= 0 {
+		req = req[:i]
+	}
+	match := requirementPattern.FindStringSubmatch(strings.TrimSpace(req))
+	if match == nil {
+		return model.Dependency{}, false
+	}
+	version := strings.TrimSpace(match[3])
+	version = strings.TrimSpace(strings.TrimPrefix(version, "@"))
+	version = strings.TrimSuffix(strings.TrimPrefix(version, "("), ")")
+	return model.Dependency{Name: match[1], Version: version}, true
+}
+
+// parseRequirements reads a pip requirements file. The scope is taken from
+// the file name, so requirements-dev.txt lists dev dependencies. Options,
+// includes and editable installs are skipped.
+func parseRequirements(name string, content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemPyPI}
+	scope := strings.TrimLeft(strings.TrimSuffix(strings.TrimPrefix(name, "requirements"), ".txt"), "-_.")
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if dep, ok := parseRequirement(line); ok {
+			dep.Scope = scope
+			m.Dependencies = append(m.Dependencies, dep)
+		}
+	}
+	return m
+}
@@ -0,0 +1,358 @@
+package manifest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// dep abbreviates a model.Dependency.
+func dep(name, version, scope string) model.Dependency {
+	return model.Dependency{Name: name, Version: version, Scope: scope}
+}
+
+func TestParseDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []model.Dependency
+	}{
+		{
+			name: "go.mod skips indirect requirements",
+			file: "go.mod",
+			content: `module example.com/app
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/sys v0.20.0 // indirect
+	"example.com/quoted" v0.1.0 // a comment
+)
+
+require golang.org/x/text v0.15.0 // indirect
+`,
+			want: []model.Dependency{
+				dep("example.com/quoted", "v0.1.0", ""),
+				dep("github.com/google/uuid", "v1.6.0", ""),
+				dep("github.com/spf13/cobra", "v1.8.0", ""),
+			},
+		},
+		{
+			name: "package.json groups",
+			file: "package.json",
+			content: `{
+  "name": "web",
+  "dependencies": {"react": "^18.2.0"},
+  "devDependencies": {"jest": "^29.0.0"},
+  "peerDependencies": {"react-dom": ">=18"}
+}`,
+			want: []model.Dependency{
+				dep("react", "^18.2.0", ""),
+				dep("jest", "^29.0.0", "dev"),
+				dep("react-dom", ">=18", "peer"),
+			},
+		},
+		{
+			name: "requirements with PEP 508 markers, extras and URLs",
+			file: "requirements-dev.txt",
+			content: `# tooling
+requests[socks]>=2.31 ; python_version > "3.8"
+pytest==8.0.0; sys_platform != 'win32'
+black  # formatter
+tomli (>=2.0)
+private @ git+https://github.com/org/private.git@v1
+-r requirements.txt
+--index-url https://example.com/simple
+-e ./local
+`,
+			want: []model.Dependency{
+				dep("black", "", "dev"),
+				dep("private", "git+https://github.com/org/private.git@v1", "dev"),
+				dep("pytest", "==8.0.0", "dev"),
+				dep("requests", ">=2.31", "dev"),
+				dep("tomli", ">=2.0", "dev"),
+			},
+		},
+		{
+			name: "pyproject with multi-line arrays and markers",
+			file: "pyproject.toml",
+			content: `[project]
+name = "svc"
+dependencies = [
+    "fastapi>=0.110",  # web
+    "uvicorn[standard]",
+    "tomli>=2; python_version < '3.11'",
+]
+
+[project.optional-dependencies]
+test = [
+    "pytest",
+]
+`,
+			want: []model.Dependency{
+				dep("fastapi", ">=0.110", ""),
+				dep("tomli", ">=2", ""),
+				dep("uvicorn", "", ""),
+				dep("pytest", "", "test"),
+			},
+		},
+		{
+			name: "Poetry dependencies and groups",
+			file: "pyproject.toml",
+			content: `[tool.poetry]
+name = "svc"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+httpx = "^0.27"
+pydantic = { version = "^2.6", extras = ["email"] }
+shared = { path = "../shared", develop = true }
+
+[tool.poetry.dev-dependencies]
+mypy = "^1.9"
+
+[tool.poetry.group.docs.dependencies]
+mkdocs = "^1.5"
+
+[tool.poetry.group.lint]
+optional = true
+`,
+			want: []model.Dependency{
+				dep("httpx", "^0.27", ""),
+				dep("pydantic", "^2.6", ""),
+				dep("shared", "path:../shared", ""),
+				dep("mypy", "^1.9", "dev"),
+				dep("mkdocs", "^1.5", "docs"),
+			},
+		},
+		{
+			name: "pyproject with multi-line strings",
+			file: "pyproject.toml",
+			content: `[project]
+name = "svc"
+description = '''
+A service.
+[tool.poetry.dependencies]
+fake = "1.0"
+'''
+readme = """
+Say "hi" # not a comment
+dependencies = ["fake"]
+Say "bye""""
+dependencies = [
+    "httpx>=0.27",  # client
+]
+`,
+			want: []model.Dependency{
+				dep("httpx", ">=0.27", ""),
+			},
+		},
+		{
+			name: "Cargo with a multi-line description",
+			file: "Cargo.toml",
+			content: `[package]
+name = "app"
+description = """
+Runs things.
+
+[dependencies]
+fake = "1"
+"""
+
+[dependencies]
+anyhow = "1"
+`,
+			want: []model.Dependency{
+				dep("anyhow", "1", ""),
+			},
+		},
+		{
+			name: "Cargo tables, target tables and workspace dependencies",
+			file: "Cargo.toml",
+			content: `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+anyhow = "1"
+tokio = { version = "1.37", features = [
+    "rt-multi-thread",
+    "macros",
+] }
+shared.workspace = true
+local = { path = "../local" }
+
+[dependencies.serde]
+version = "1.0"
+features = ["derive"]
+
+[dev-dependencies]
+criterion = "0.5"
+
+[build-dependencies]
+cc = "1.0"
+
+[target.'cfg(unix)'.dependencies]
+nix = "0.28"
+
+[target."cfg(windows)".dev-dependencies]
+winapi = { version = "0.3" }
+
+[workspace.dependencies]
+regex = "1.10"
+`,
+			want: []model.Dependency{
+				dep("anyhow", "1", ""),
+				dep("local", "path:../local", ""),
+				dep("nix", "0.28", ""),
+				dep("serde", "1.0", ""),
+				dep("shared", "workspace", ""),
+				dep("tokio", "1.37", ""),
+				dep("cc", "1.0", "build"),
+				dep("criterion", "0.5", "dev"),
+				dep("winapi", "0.3", "dev"),
+				dep("regex", "1.10", "workspace"),
+			},
+		},
+		{
+			name: "pom.xml resolves properties",
+			file: "pom.xml",
+			content: `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>2.0.0</version>
+  <properties>
+    <spring.version>6.1.5</spring.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework</groupId>
+      <artifactId>spring-core</artifactId>
+      <version>${spring.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>shared</artifactId>
+      <version>${project.version}</version>
+      <scope>compile</scope>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>${junit.version}</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>`,
+			want: []model.Dependency{
+				dep("com.example:shared", "2.0.0", ""),
+				dep("org.springframework:spring-core", "6.1.5", ""),
+				dep("org.junit.jupiter:junit-jupiter", "${junit.version}", "test"),
+			},
+		},
+		{
+			name: "build.gradle string and map notation",
+			file: "build.gradle",
+			content: `dependencies {
+    implementation 'com.google.guava:guava:33.0.0-jre'
+    testImplementation("org.junit.jupiter:junit-jupiter:5.10.2")
+    compileOnly group: 'org.projectlombok', name: 'lombok', version: '1.18.30'
+    implementation project(':core')
+}
+`,
+			want: []model.Dependency{
+				dep("com.google.guava:guava", "33.0.0-jre", ""),
+				dep("org.projectlombok:lombok", "1.18.30", "provided"),
+				dep("org.junit.jupiter:junit-jupiter", "5.10.2", "test"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.file, []byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Dependencies, tt.want) {
+				t.Errorf("dependencies =\n  %v\nwant\n  %v", m.Dependencies, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseModules(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		module    string
+		version   string
+		workspace []string
+	}{
+		{"go.mod", "go.mod", "module \"example.com/app\"\n", "example.com/app", "", nil},
+		{"go.work", "go.work", "go 1.22\n\nuse (\n\t./api\n\t./web // frontend\n)\nuse ./tools\n", "", "", []string{"./api", "./web", "./tools"}},
+		{"npm workspaces", "package.json", `{"name": "root", "version": "1.0.0", "workspaces": ["packages/*"]}`, "root", "1.0.0", []string{"packages/*"}},
+		{"yarn workspaces", "package.json", `{"workspaces": {"packages": ["apps/*"]}}`, "", "", []string{"apps/*"}},
+		{"pnpm workspace", "pnpm-workspace.yaml", "packages:\n  - 'packages/*'\n  - \"!**/test/**\"\nother:\n  - x\n", "", "", []string{"packages/*"}},
+		{"cargo multi-line description", "Cargo.toml", "[package]\nname = \"app\"\ndescription = \"\"\"\nversion = \"9\"\n\"\"\"\nversion = \"0.2.0\"\n", "app", "0.2.0", nil},
+		{"cargo workspace", "Cargo.toml", "[workspace]\nmembers = [\n  \"crates/a\",\n  \"crates/b\",\n]\n", "", "", []string{"crates/a", "crates/b"}},
+		{"maven parent version", "pom.xml", "<project><parent><groupId>com.example</groupId><version>3.1</version></parent><artifactId>child</artifactId><modules><module>api</module></modules></project>", "com.example:child", "3.1", []string{"api"}},
+		{"gradle settings", "settings.gradle.kts", "rootProject.name = \"app\"\ninclude(\":app\", \":lib:core\")\ninclude ':cli'\n", "", "", []string{"app", "lib/core", "cli"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.file, []byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Module != tt.module || m.Version != tt.version || !reflect.DeepEqual(m.Workspace, tt.workspace) {
+				t.Errorf("module %q, version %q, workspace %q; want %q, %q, %q", m.Module, m.Version, m.Workspace, tt.module, tt.version, tt.workspace)
+			}
+		})
+	}
+}
+
+func TestParseCargoBinaries(t *testing.T) {
+	content := `[package]
+name = "app"
+
+[[bin]]
+name = "app-server"
+path = "cmd/server.rs"
+
+[[bin]]
+name = "migrate"
+required-features = ["db"]
+
+[dependencies]
+anyhow = "1"
+`
+	m, err := Parse("Cargo.toml", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Binary{{Name: "app-server", Path: "cmd/server.rs"}, {Name: "migrate"}}
+	if !reflect.DeepEqual(m.Binaries, want) {
+		t.Errorf("binaries = %v, want %v", m.Binaries, want)
+	}
+	if len(m.Dependencies) != 1 {
+		t.Errorf("dependencies = %v, want anyhow only", m.Dependencies)
+	}
+}
+
+func TestParseRejectsInvalidManifests(t *testing.T) {
+	for _, tt := range []struct{ file, content string }{
+		{"package.json", "{"},
+		{"pom.xml", "<project>"},
+		{"setup.py", ""},
+	} {
+		if _, err := Parse(tt.file, []byte(tt.content)); err == nil {
+			t.Errorf("Parse(%s, %q) succeeded, want an error", tt.file, tt.content)
+		}
+	}
+}
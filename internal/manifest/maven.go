@@ -0,0 +1,179 @@
+package manifest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// pomProject holds the parts of a Maven pom.xml that matter here.
+// Dependencies under dependencyManagement only pin versions and are not read.
+type pomProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties   pomProperties   `xml:"properties"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+	Modules      []string        `xml:"modules>module"`
+}
+
+// pomDependency is one dependency of a pom.xml.
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// pomProperties are the user-defined properties of a pom.xml, used in
+// ${name} references.
+type pomProperties map[string]string
+
+// UnmarshalXML reads every child element of <properties> as a property.
+func (p *pomProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = make(pomProperties)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// pomReference matches a ${name} property reference.
+var pomReference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// parsePom reads the direct dependencies of a Maven pom.xml, resolving
+// property references that the file itself defines.
+func parsePom(content []byte) (*model.Manifest, error) {
+	var pom pomProject
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil, fmt.Errorf("invalid pom.xml: %w", err)
+	}
+	if pom.GroupID == "" {
+		pom.GroupID = pom.Parent.GroupID
+	}
+	if pom.Version == "" {
+		pom.Version = pom.Parent.Version
+	}
+	properties := pom.Properties
+	if properties == nil {
+		properties = make(pomProperties)
+	}
+	properties["project.groupId"] = pom.GroupID
+	properties["project.artifactId"] = pom.ArtifactID
+	properties["project.version"] = pom.Version
+	properties["project.parent.version"] = pom.Parent.Version
+	resolve := func(s string) string {
+		return pomReference.ReplaceAllStringFunc(strings.TrimSpace(s), func(ref string) string {
+			if value, ok := properties[ref[2:len(ref)-1]]; ok && value != "" {
+				return value
+			}
+			return ref
+		})
+	}
+
+	m := &model.Manifest{Ecosystem: EcosystemMaven, Version: resolve(pom.Version)}
+	for _, module := range pom.Modules {
+		m.Workspace = append(m.Workspace, strings.TrimSpace(module))
+	}
+	if pom.ArtifactID != "" {
+		m.Module = resolve(pom.GroupID) + ":" + resolve(pom.ArtifactID)
+	}
+	for _, dep := range pom.Dependencies {
+		scope := strings.TrimSpace(dep.Scope)
+		if scope == "compile" {
+			scope = ""
+		}
+		m.Dependencies = append(m.Dependencies, model.Dependency{
+			Name:    resolve(dep.GroupID) + ":" + resolve(dep.ArtifactID),
+			Version: resolve(dep.Version),
+			Scope:   scope,
+		})
+	}
+	return m, nil
+}
+
+// gradleConfigurations are the Gradle configurations that declare
+// dependencies, with the scope they map to.
+var gradleConfigurations = map[string]string{
+	"implementation":            "",
+	"api":                       "",
+	"compile":                   "",
+	"compileOnly":               "provided",
+	"runtimeOnly":               "runtime",
+	"runtime":                   "runtime",
+	"annotationProcessor":       "annotation-processor",
+	"kapt":                      "annotation-processor",
+	"ksp":                       "annotation-processor",
+	"testImplementation":        "test",
+	"testCompile":               "test",
+	"testCompileOnly":           "test",
+	"testRuntimeOnly":           "test",
+	"androidTestImplementation": "test",
+}
+
+// Gradle dependency declarations, in string notation,
+// implementation("group:name:version"), and in map notation,
+// implementation group: 'group', name: 'name', version: 'version'.
+var (
+	gradleString = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*["']([^"':\s]+):([^"':\s]+)(?::([^"'\s]+))?["']`)
+	gradleMap    = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*group\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["'](?:\s*,\s*version\s*[:=]\s*["']([^"']+)["'])?`)
+)
+
+// parseGradle reads the external dependencies of a build.gradle or
+// build.gradle.kts file. Versions defined in variables or version catalogs
+// are reported as written.
+func parseGradle(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemMaven}
+	for _, pattern := range []*regexp.Regexp{gradleString, gradleMap} {
+		for _, match := range pattern.FindAllStringSubmatch(string(content), -1) {
+			scope, ok := gradleConfigurations[match[1]]
+			if !ok {
+				continue
+			}
+			m.Dependencies = append(m.Dependencies, model.Dependency{
+				Name:    match[2] + ":" + match[3],
+				Version: match[4],
+				Scope:   scope,
+			})
+		}
+	}
+	return m
+}
+
+// gradleInclude matches the project paths of an include statement in a
+// Gradle settings file: include("app", ":lib:core") or include ':app'.
+var (
+	gradleInclude = regexp.MustCompile(`(?m)^\s*include\b\s*\(?([^)\n]*)`)
+	gradleProject = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// parseGradleSettings reads the projects a Gradle settings file includes,
+// as directories: :lib:core is lib/core.
+func parseGradleSettings(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemMaven}
+	for _, include := range gradleInclude.FindAllStringSubmatch(string(content), -1) {
+		for _, project := range gradleProject.FindAllStringSubmatch(include[1], -1) {
+			m.Workspace = append(m.Workspace, strings.ReplaceAll(strings.TrimPrefix(project[1], ":"), ":", "/"))
+		}
+	}
+	return m
+}
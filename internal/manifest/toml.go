@@ -0,0 +1,395 @@
+package manifest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// tomlEntry is a key of a TOML document, the table it is in and its value as
+// written. Only what manifests use is understood: tables, strings, arrays
+// and inline tables, possibly spanning several lines.
+type tomlEntry struct {
+	table string
+	key   string
+	value string
+	// item numbers the elements of an array of tables, such as [[bin]],
+	// from 1, and is 0 in other tables.
+	item int
+}
+
+// parseTOML splits a TOML document into its entries.
+func parseTOML(content []byte) []tomlEntry {
+	var entries []tomlEntry
+	table := ""
+	item := 0
+	items := make(map[string]int)
+	var pending strings.Builder
+	depth := 0
+	// open is the delimiter of a multi-line string the previous lines left
+	// open, whose lines are kept as they are.
+	open := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		inString := open != ""
+		line = stripTOMLComment(line, open)
+		if !inString {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+		}
+		if !inString && depth == 0 && strings.HasPrefix(line, "[") {
+			table = unquoteKey(strings.Trim(line, "[] "))
+			item = 0
+			if strings.HasPrefix(line, "[[") {
+				items[table]++
+				item = items[table]
+			}
+			continue
+		}
+		if inString {
+			pending.WriteString("\n")
+		} else if pending.Len() > 0 {
+			pending.WriteString(" ")
+		}
+		pending.WriteString(line)
+		var lineDepth int
+		lineDepth, open = nesting(line, open)
+		depth += lineDepth
+		if depth > 0 || open != "" {
+			continue
+		}
+		statement := pending.String()
+		pending.Reset()
+		depth = 0
+		key, value, ok := cutOutsideQuotes(statement, '=')
+		if !ok {
+			continue
+		}
+		entries = append(entries, tomlEntry{table: table, key: unquoteKey(strings.TrimSpace(key)), value: strings.TrimSpace(value), item: item})
+	}
+	return entries
+}
+
+// scanTOML calls visit with the index of every byte of line outside strings,
+// until visit returns false. open is the delimiter of a multi-line string
+// that line starts in, three double or single quotes, or "". It returns the
+// delimiter of the multi-line string still open at the end of line, or "".
+func scanTOML(line, open string, visit func(i int) bool) string {
+	quote := open
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if quote != "" {
+			if c == '\\' && quote[0] == '"' {
+				i++
+			} else if strings.HasPrefix(line[i:], quote) {
+				// Up to two quotes may end the content of a multi-line
+				// string right before its delimiter.
+				for len(quote) == 3 && i+3 < len(line) && line[i+3] == c {
+					i++
+				}
+				i += len(quote) - 1
+				quote = ""
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line[i:], `"""`) || strings.HasPrefix(line[i:], "'''"):
+			quote = line[i : i+3]
+			i += 2
+		case c == '"' || c == '\'':
+			quote = string(c)
+		default:
+			if !visit(i) {
+				return ""
+			}
+		}
+	}
+	if len(quote) == 1 {
+		// Other strings end with their line.
+		return ""
+	}
+	return quote
+}
+
+// stripTOMLComment removes a trailing # comment that is not inside a string.
+// open is the delimiter of a multi-line string line starts in, or "".
+func stripTOMLComment(line, open string) string {
+	end := len(line)
+	scanTOML(line, open, func(i int) bool {
+		if line[i] == '#' {
+			end = i
+			return false
+		}
+		return true
+	})
+	return line[:end]
+}
+
+// nesting returns how many more brackets and braces line opens than closes,
+// outside strings, and the delimiter of the multi-line string it leaves
+// open. open is the delimiter of the one line starts in, or "".
+func nesting(line, open string) (int, string) {
+	depth := 0
+	open = scanTOML(line, open, func(i int) bool {
+		switch line[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+		return true
+	})
+	return depth, open
+}
+
+// cutOutsideQuotes splits s around the first sep that is not inside a
+// string.
+func cutOutsideQuotes(s string, sep byte) (string, string, bool) {
+	parts := splitTopLevel(s, sep)
+	if len(parts) < 2 {
+		return s, "", false
+	}
+	return parts[0], s[len(parts[0])+1:], true
+}
+
+// splitTopLevel splits s at every sep that is neither inside a string nor
+// nested in brackets or braces.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	scanTOML(s, "", func(i int) bool {
+		switch c := s[i]; {
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+		return true
+	})
+	return append(parts, s[start:])
+}
+
+// unquoteKey removes the quotes around the parts of a dotted key or table
+// name, so [target.'cfg(unix)'.dependencies] becomes target.cfg(unix).dependencies.
+func unquoteKey(key string) string {
+	parts := splitTopLevel(key, '.')
+	for i, part := range parts {
+		parts[i] = tomlString(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString returns the text of a string value, or the value as written
+// when it is not a string.
+func tomlString(value string) string {
+	value = strings.TrimSpace(value)
+	for _, delim := range []string{`"""`, "'''"} {
+		if len(value) >= 6 && strings.HasPrefix(value, delim) && strings.HasSuffix(value, delim) {
+			// A newline right after the opening delimiter is not content.
+			return strings.TrimPrefix(value[3:len(value)-3], "\n")
+		}
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1]
+	}
+	if len(value) >= 2 && value[0] == '"' {
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+		return strings.Trim(value, `"`)
+	}
+	return value
+}
+
+// tomlArray returns the items of an array value, as strings.
+func tomlArray(value string) []string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil
+	}
+	var items []string
+	for _, item := range splitTopLevel(value[1:len(value)-1], ',') {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, tomlString(item))
+		}
+	}
+	return items
+}
+
+// tomlTable returns the keys of an inline table value and their values as
+// strings.
+func tomlTable(value string) map[string]string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		return nil
+	}
+	table := make(map[string]string)
+	for _, item := range splitTopLevel(value[1:len(value)-1], ',') {
+		if key, v, ok := cutOutsideQuotes(item, '='); ok {
+			table[unquoteKey(strings.TrimSpace(key))] = tomlString(v)
+		}
+	}
+	return table
+}
+
+// parsePyProject reads the dependencies of a pyproject.toml file, declared
+// either in the standard [project] table or for Poetry.
+func parsePyProject(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemPyPI}
+	for _, e := range parseTOML(content) {
+		switch {
+		case e.table == "project" || e.table == "tool.poetry":
+			switch e.key {
+			case "name":
+				m.Module = tomlString(e.value)
+			case "version":
+				m.Version = tomlString(e.value)
+			case "dependencies":
+				m.Dependencies = appendRequirements(m.Dependencies, tomlArray(e.value), "")
+			}
+		case e.table == "project.optional-dependencies" || e.table == "dependency-groups":
+			m.Dependencies = appendRequirements(m.Dependencies, tomlArray(e.value), e.key)
+		case e.table == "tool.poetry.dependencies":
+			m.Dependencies = appendPoetry(m.Dependencies, e, "")
+		case e.table == "tool.poetry.dev-dependencies":
+			m.Dependencies = appendPoetry(m.Dependencies, e, "dev")
+		case strings.HasPrefix(e.table, "tool.poetry.group.") && strings.HasSuffix(e.table, ".dependencies"):
+			group := strings.TrimSuffix(strings.TrimPrefix(e.table, "tool.poetry.group."), ".dependencies")
+			m.Dependencies = appendPoetry(m.Dependencies, e, group)
+		}
+	}
+	return m
+}
+
+// appendRequirements appends the PEP 508 requirements of a list.
+func appendRequirements(deps []model.Dependency, reqs []string, scope string) []model.Dependency {
+	for _, req := range reqs {
+		if dep, ok := parseRequirement(req); ok {
+			dep.Scope = scope
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// appendPoetry appends a Poetry dependency, written as a version or as a
+// table with one. The Python version requirement is not a dependency.
+func appendPoetry(deps []model.Dependency, e tomlEntry, scope string) []model.Dependency {
+	if e.key == "python" {
+		return deps
+	}
+	return append(deps, model.Dependency{Name: e.key, Version: dependencyVersion(e.value), Scope: scope})
+}
+
+// dependencyVersion returns the version of a TOML dependency, written either
+// as a string or as an inline table; dependencies taken from a path, a git
+// repository or the workspace say so instead.
+func dependencyVersion(value string) string {
+	table := tomlTable(value)
+	if table == nil {
+		return tomlString(value)
+	}
+	switch {
+	case table["version"] != "":
+		return table["version"]
+	case table["workspace"] == "true":
+		return "workspace"
+	case table["path"] != "":
+		return "path:" + table["path"]
+	case table["git"] != "":
+		return "git:" + table["git"]
+	}
+	return ""
+}
+
+// cargoScopes maps the dependency tables of Cargo.toml to scopes.
+var cargoScopes = map[string]string{
+	"dependencies":       "",
+	"dev-dependencies":   "dev",
+	"build-dependencies": "build",
+}
+
+// parseCargo reads the dependencies of a Cargo.toml file, including
+// target-specific ones and those a workspace shares with its members, and
+// the binaries it declares.
+func parseCargo(content []byte) *model.Manifest {
+	m := &model.Manifest{Ecosystem: EcosystemCargo}
+	// Dependencies written as tables, [dependencies.serde], are gathered
+	// from the keys that follow.
+	tables := make(map[string]*model.Dependency)
+	var order []string
+	for _, e := range parseTOML(content) {
+		if e.table == "package" {
+			switch e.key {
+			case "name":
+				m.Module = tomlString(e.value)
+			case "version":
+				m.Version = tomlString(e.value)
+			}
+			continue
+		}
+		if e.table == "workspace" && e.key == "members" {
+			m.Workspace = tomlArray(e.value)
+			continue
+		}
+		if e.table == "bin" && e.item > 0 {
+			for len(m.Binaries) < e.item {
+				m.Binaries = append(m.Binaries, model.Binary{})
+			}
+			switch e.key {
+			case "name":
+				m.Binaries[e.item-1].Name = tomlString(e.value)
+			case "path":
+				m.Binaries[e.item-1].Path = tomlString(e.value)
+			}
+			continue
+		}
+		if e.table == "workspace.dependencies" {
+			m.Dependencies = append(m.Dependencies, cargoDependency(e.key, e.value, "workspace"))
+			continue
+		}
+		table := e.table
+		if i := strings.LastIndex(table, "."); strings.HasPrefix(table, "target.") && i > 0 {
+			table = table[i+1:]
+		}
+		if scope, ok := cargoScopes[table]; ok {
+			m.Dependencies = append(m.Dependencies, cargoDependency(e.key, e.value, scope))
+			continue
+		}
+		// A table named after a dependency: [dependencies.serde].
+		if i := strings.LastIndex(e.table, "."); i > 0 {
+			if scope, ok := cargoScopes[e.table[strings.LastIndex(e.table[:i], ".")+1:i]]; ok {
+				if strings.HasPrefix(e.table, "workspace.") {
+					scope = "workspace"
+				}
+				dep, seen := tables[e.table]
+				if !seen {
+					dep = &model.Dependency{Name: e.table[i+1:], Scope: scope}
+					tables[e.table] = dep
+					order = append(order, e.table)
+				}
+				if version := dependencyVersion("{" + e.key + " = " + e.value + "}"); version != "" && dep.Version == "" {
+					dep.Version = version
+				}
+			}
+		}
+	}
+	for _, name := range order {
+		m.Dependencies = append(m.Dependencies, *tables[name])
+	}
+	return m
+}
+
+// cargoDependency reads one entry of a dependency table. Dotted keys, such
+// as serde.workspace = true, are folded into a table.
+func cargoDependency(key, value, scope string) model.Dependency {
+	if name, field, ok := strings.Cut(key, "."); ok {
+		return model.Dependency{Name: name, Version: dependencyVersion("{" + field + " = " + value + "}"), Scope: scope}
+	}
+	return model.Dependency{Name: key, Version: dependencyVersion(value), Scope: scope}
+}
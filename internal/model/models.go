@@ -13,6 +13,17 @@ type Language struct {
 	Name           string          `json:"name"`
 	FileExtensions []string        `json:"file_extensions"`
 	Queries        []LanguageQuery `json:"queries,omitempty"`
+	// ImportQueries capture the imported module or path as @path.
+	ImportQueries []string `json:"import_queries,omitempty"`
+	// RouteQueries capture an HTTP route definition as @route, its path as
+	// @path, and its method as @method or @methods and its handler as
+	// @handler where they are written, and the router it is registered on
+	// as @receiver. Patterns capturing @prefix instead prepend a path to the
+	// routes inside their @scope or registered on their @group variable,
+	// and patterns capturing @router name the variables holding routers;
+	// when a file has any, routes on other receivers are dropped. When
+	// several patterns match one route, the first one wins.
+	RouteQueries []string `json:"route_queries,omitempty"`
 }
 
 // LanguageConfig holds all language configurations.
@@ -22,41 +33,481 @@ type LanguageConfig struct {
 
 // CodeElement represents a single parsed entity from a source code file.
 type CodeElement struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	Line int    `json:"line"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"`
+	// Cyclomatic and Cognitive measure the complexity of functions and
+	// methods; they are zero for other elements.
+	Cyclomatic int `json:"cyclomatic,omitempty"`
+	Cognitive  int `json:"cognitive,omitempty"`
+	// Hotspot is set when Cognitive reaches the configured threshold.
+	Hotspot bool `json:"hotspot,omitempty"`
+	// Fingerprint summarizes the body of a function for duplicate detection.
+	Fingerprint *BodyFingerprint `json:"-"`
+}
+
+// BodyFingerprint describes the normalized token stream of a function body,
+// in which identifiers and literals are replaced by placeholders.
+type BodyFingerprint struct {
+	// Hash identifies the whole token stream; equal hashes mean copies.
+	Hash    uint64
+	Tokens  int
+	EndLine int
+	// Winnow holds the winnowing hashes of the stream, for near copies.
+	Winnow []uint64
+}
+
+// ElementChange describes how a code element differs from the base ref.
+type ElementChange struct {
+	Change       string `json:"change"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Line         int    `json:"line,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+	OldSignature string `json:"old_signature,omitempty"`
 }
 
 // Node represents a single item in the file system tree.
 type Node struct {
-	Name         string        `json:"name"`
-	Path         string        `json:"path"`
-	IsDir        bool          `json:"is_dir"`
-	LOC          int           `json:"lines_of_code,omitempty"`
-	Children     []*Node       `json:"children,omitempty"`
-	CodeElements []CodeElement `json:"elements,omitempty"`
+	Name           string          `json:"name"`
+	Path           string          `json:"path"`
+	IsDir          bool            `json:"is_dir"`
+	LOC            int             `json:"lines_of_code,omitempty"`
+	CodeLines      int             `json:"code_lines,omitempty"`
+	CommentLines   int             `json:"comment_lines,omitempty"`
+	BlankLines     int             `json:"blank_lines,omitempty"`
+	Children       []*Node         `json:"children,omitempty"`
+	CodeElements   []CodeElement   `json:"elements,omitempty"`
+	Imports        []string        `json:"imports,omitempty"`
+	Skeleton       string          `json:"skeleton,omitempty"`
+	Hash           string          `json:"hash,omitempty"`
+	Status         string          `json:"status,omitempty"`
+	OldPath        string          `json:"old_path,omitempty"`
+	ElementChanges []ElementChange `json:"element_changes,omitempty"`
+	// Skipped says why a file was listed but not analyzed: binary,
+	// generated, minified or too large.
+	Skipped string `json:"skipped,omitempty"`
+	// Module names the module a file belongs to, or that a directory is
+	// the root of.
+	Module string `json:"module,omitempty"`
+	// Routes lists the HTTP endpoints the file defines.
+	Routes []Route `json:"routes,omitempty"`
+	// Annotations lists the TODO, FIXME, HACK, XXX and deprecation
+	// comments of the file.
+	Annotations []Annotation `json:"annotations,omitempty"`
+	// History summarizes how the file changed in git over the analyzed
+	// window; nil unless history was requested or the file has none.
+	History *FileHistory `json:"history,omitempty"`
+}
+
+// FileHistory is how a file changed in git over a window: its commits, the
+// date of the last one (YYYY-MM-DD), who made most of them and the lines
+// added and deleted.
+type FileHistory struct {
+	Commits      int             `json:"commits"`
+	LastModified string          `json:"last_modified"`
+	TopAuthors   []AuthorCommits `json:"top_authors,omitempty"`
+	LinesAdded   int             `json:"lines_added"`
+	LinesDeleted int             `json:"lines_deleted"`
+}
+
+// AuthorCommits is the number of commits an author made to a file.
+type AuthorCommits struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+// HistoryReport summarizes the git history of a tree over a window, given
+// as requested (e.g. 90d or all) and as the date it starts on.
+type HistoryReport struct {
+	Window string `json:"window"`
+	Since  string `json:"since,omitempty"`
+	// Commits is the number of commits in the window.
+	Commits int `json:"commits"`
+	// HotFiles ranks the files that change often and are complex, hottest
+	// first.
+	HotFiles []HotFile `json:"hot_files,omitempty"`
+}
+
+// HotFile is a file ranked by churn times complexity: its commits in the
+// window times the cognitive complexity of its functions.
+type HotFile struct {
+	Path         string `json:"path"`
+	Commits      int    `json:"commits"`
+	LinesChanged int    `json:"lines_changed"`
+	Complexity   int    `json:"complexity"`
+	Score        int    `json:"score"`
+}
+
+// Route is an HTTP endpoint: the method, or ANY, the path, the function
+// that handles it when it is named, and the line it is defined on.
+type Route struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler,omitempty"`
+	Line    int    `json:"line"`
+}
+
+// Annotation is a tagged comment, such as "// TODO(ana): retry on EOF".
+// Element names the element the comment is in or documents. Author and
+// Date come from git blame when requested; Date is YYYY-MM-DD.
+type Annotation struct {
+	Tag     string `json:"tag"`
+	Text    string `json:"text,omitempty"`
+	Owner   string `json:"owner,omitempty"`
+	Line    int    `json:"line"`
+	Element string `json:"element,omitempty"`
+	Author  string `json:"author,omitempty"`
+	Date    string `json:"date,omitempty"`
+}
+
+// FileAnnotation is an Annotation of the tech debt list of a tree, with
+// its file.
+type FileAnnotation struct {
+	File string `json:"file"`
+	Annotation
+}
+
+// Endpoint is a Route of the API surface of a tree, with its file.
+type Endpoint struct {
+	File string `json:"file"`
+	Route
 }
 
 // LanguageStats holds analytics for a specific language.
 type LanguageStats struct {
 	FileCount     int            `json:"file_count"`
 	LOC           int            `json:"lines_of_code"`
+	CodeLines     int            `json:"code_lines"`
+	CommentLines  int            `json:"comment_lines"`
+	BlankLines    int            `json:"blank_lines"`
+	Tests         int            `json:"tests,omitempty"`
 	ElementCounts map[string]int `json:"element_counts,omitempty"`
 }
 
 // Analytics holds comprehensive statistics about the analysis process.
 type Analytics struct {
-	FilesScanned     int                      `json:"files_scanned"`
-	FilesParsed      int                      `json:"files_parsed"`
-	TotalLOC         int                      `json:"total_lines_of_code"`
-	TotalElements    int                      `json:"total_elements"`
-	PerLanguageStats map[string]LanguageStats `json:"language_stats,omitempty"`
-	Duration         time.Duration            `json:"duration_nanoseconds"`
-	DurationReadable string                   `json:"duration_readable"`
+	FilesScanned      int                      `json:"files_scanned"`
+	FilesParsed       int                      `json:"files_parsed"`
+	FilesSkipped      int                      `json:"files_skipped,omitempty"`
+	TotalLOC          int                      `json:"total_lines_of_code"`
+	TotalCodeLines    int                      `json:"total_code_lines"`
+	TotalCommentLines int                      `json:"total_comment_lines"`
+	TotalBlankLines   int                      `json:"total_blank_lines"`
+	TotalElements     int                      `json:"total_elements"`
+	TestFiles         int                      `json:"test_files,omitempty"`
+	Tests             int                      `json:"tests,omitempty"`
+	PerLanguageStats  map[string]LanguageStats `json:"language_stats,omitempty"`
+	PerModuleStats    map[string]ModuleStats   `json:"module_stats,omitempty"`
+	Duration          time.Duration            `json:"duration_nanoseconds"`
+	DurationReadable  string                   `json:"duration_readable"`
+}
+
+// Redaction records a secret that was replaced by a placeholder in the output.
+type Redaction struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+}
+
+// ChangeSummary totals the file and element changes of a changed-since analysis.
+type ChangeSummary struct {
+	Base              string `json:"base"`
+	Staged            bool   `json:"staged,omitempty"`
+	FilesAdded        int    `json:"files_added"`
+	FilesModified     int    `json:"files_modified"`
+	FilesDeleted      int    `json:"files_deleted"`
+	FilesRenamed      int    `json:"files_renamed"`
+	ElementsAdded     int    `json:"elements_added"`
+	ElementsRemoved   int    `json:"elements_removed"`
+	SignaturesChanged int    `json:"signatures_changed"`
 }
 
 // AnalysisResult is the top-level struct for JSON output.
 type AnalysisResult struct {
-	Root      *Node     `json:"tree"`
-	Analytics Analytics `json:"analytics"`
+	Root       *Node          `json:"tree"`
+	Analytics  Analytics      `json:"analytics"`
+	Changes    *ChangeSummary `json:"changes,omitempty"`
+	Redactions []Redaction    `json:"redactions,omitempty"`
+	// Incomplete is set when the analysis was interrupted or timed out, and
+	// IncompleteReason says which.
+	Incomplete       bool   `json:"incomplete,omitempty"`
+	IncompleteReason string `json:"incomplete_reason,omitempty"`
+	// Diagnostics lists the problems met while analyzing individual files.
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	// Complexity ranks the most complex functions of the tree.
+	Complexity *ComplexityReport `json:"complexity,omitempty"`
+	// Duplicates groups functions whose bodies are copies or near copies.
+	Duplicates []DuplicateCluster `json:"duplicates,omitempty"`
+	// Tests maps test files to the source files they exercise.
+	Tests *TestReport `json:"tests,omitempty"`
+	// Dependencies lists the dependency manifests found in the tree.
+	Dependencies []Manifest `json:"dependencies,omitempty"`
+	// Modules lists the modules and workspaces of a monorepo.
+	Modules []Module `json:"modules,omitempty"`
+	// EntryPoints lists where programs start and frameworks are set up.
+	EntryPoints []EntryPoint `json:"entry_points,omitempty"`
+	// APISurface lists the HTTP endpoints of the tree.
+	APISurface []Endpoint `json:"api_surface,omitempty"`
+	// TechDebt lists the annotations of the tree, by tag and directory.
+	TechDebt []FileAnnotation `json:"tech_debt,omitempty"`
+	// History summarizes the git history of the tree when requested.
+	History *HistoryReport `json:"history,omitempty"`
+}
+
+// EntryPoint is a place to start reading a codebase: a main function or
+// script, a web framework application or a CLI command. Type is the element
+// type that found it and Kind describes it for readers.
+type EntryPoint struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// Dependency is a third-party library declared by a manifest. Version is the
+// requirement as written, and Scope the group it belongs to, such as dev or
+// test, when it is not a plain runtime dependency.
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// Manifest is a dependency manifest, such as go.mod or package.json, and the
+// module it describes.
+type Manifest struct {
+	Path         string       `json:"path"`
+	Ecosystem    string       `json:"ecosystem"`
+	Module       string       `json:"module,omitempty"`
+	Version      string       `json:"version,omitempty"`
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+	// Workspace lists the member directories, or patterns matching them, of
+	// a workspace the manifest declares.
+	Workspace []string `json:"workspace,omitempty"`
+	// Binaries lists the executables declared by the [[bin]] tables of a
+	// Cargo manifest.
+	Binaries []Binary `json:"binaries,omitempty"`
+}
+
+// Binary is an executable target of a manifest. Path is relative to the
+// manifest's directory, and empty when the default location applies.
+type Binary struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+}
+
+// Module is a module, package, crate or project of a tree, rooted at the
+// directory of its manifests. Dependencies and Members name other modules
+// of the same tree.
+type Module struct {
+	Name         string   `json:"name"`
+	Path         string   `json:"path"`
+	Ecosystems   []string `json:"ecosystems"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Members      []string `json:"members,omitempty"`
+}
+
+// ModuleStats holds analytics for the files of one module.
+type ModuleStats struct {
+	FileCount        int                      `json:"file_count"`
+	LOC              int                      `json:"lines_of_code"`
+	TotalElements    int                      `json:"total_elements"`
+	PerLanguageStats map[string]LanguageStats `json:"language_stats,omitempty"`
+}
+
+// TestMapping links a test file, and the number of tests in it, to the
+// source files it exercises.
+type TestMapping struct {
+	Test    string   `json:"test"`
+	Tests   int      `json:"tests"`
+	Sources []string `json:"sources"`
+}
+
+// TestReport maps the test files of a tree to source files and lists the
+// source files, out of SourceFiles, that no test exercises.
+type TestReport struct {
+	Mappings    []TestMapping `json:"mappings,omitempty"`
+	SourceFiles int           `json:"source_files"`
+	Untested    []string      `json:"untested,omitempty"`
+}
+
+// DuplicateFunction locates one function of a DuplicateCluster.
+type DuplicateFunction struct {
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line"`
+	Tokens  int    `json:"tokens"`
+}
+
+// DuplicateCluster is a group of functions with the same or similar bodies.
+// Exact clusters have identical token streams up to identifier and literal
+// names; Similarity is the lowest similarity that joined the cluster.
+type DuplicateCluster struct {
+	Exact      bool                `json:"exact"`
+	Similarity float64             `json:"similarity"`
+	Functions  []DuplicateFunction `json:"functions"`
+}
+
+// ComplexFunction locates a function or method and its complexity.
+type ComplexFunction struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Line       int    `json:"line"`
+	Cyclomatic int    `json:"cyclomatic"`
+	Cognitive  int    `json:"cognitive"`
+}
+
+// ComplexityReport lists the most complex functions, by cognitive then
+// cyclomatic complexity, and counts the hotspots at or above Threshold.
+type ComplexityReport struct {
+	Threshold int               `json:"threshold,omitempty"`
+	Hotspots  int               `json:"hotspots"`
+	Top       []ComplexFunction `json:"top"`
+}
+
+// Diagnostic records a problem met during an analysis: which file, in which
+// phase, what kind of problem and a human-readable message.
+type Diagnostic struct {
+	Path    string `json:"path,omitempty"`
+	Phase   string `json:"phase"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// SyntaxError locates an ERROR or MISSING node in a parse tree.
+type SyntaxError struct {
+	Line   int
+	Column int
+	// Missing is set when the parser had to assume Token, which is absent
+	// from the source; otherwise the source could not be parsed at this point.
+	Missing bool
+	Token   string
+}
+
+// LineCounts classifies the physical lines of a file the way cloc does: a
+// line holding any code is code, a line holding only comments is a comment
+// line and a line holding only whitespace is blank.
+type LineCounts struct {
+	Code    int
+	Comment int
+	Blank   int
+}
+
+// Physical returns the number of lines in the file.
+func (c LineCounts) Physical() int {
+	return c.Code + c.Comment + c.Blank
+}
+
+// Record types of the ndjson format.
+const (
+	RecordFile      = "file"
+	RecordAnalytics = "analytics"
+)
+
+// FileRecord is the ndjson line describing a single file.
+type FileRecord struct {
+	Type           string          `json:"type"`
+	Path           string          `json:"path"`
+	Language       string          `json:"language,omitempty"`
+	LOC            int             `json:"lines_of_code"`
+	CodeLines      int             `json:"code_lines"`
+	CommentLines   int             `json:"comment_lines"`
+	BlankLines     int             `json:"blank_lines"`
+	Elements       []CodeElement   `json:"elements"`
+	Imports        []string        `json:"imports,omitempty"`
+	Hash           string          `json:"hash,omitempty"`
+	Status         string          `json:"status,omitempty"`
+	OldPath        string          `json:"old_path,omitempty"`
+	ElementChanges []ElementChange `json:"element_changes,omitempty"`
+	Skipped        string          `json:"skipped,omitempty"`
+	Module         string          `json:"module,omitempty"`
+	Routes         []Route         `json:"routes,omitempty"`
+	Annotations    []Annotation    `json:"annotations,omitempty"`
+	History        *FileHistory    `json:"history,omitempty"`
+}
+
+// AnalyticsRecord is the last ndjson line, summarizing the whole analysis.
+type AnalyticsRecord struct {
+	Type             string             `json:"type"`
+	Root             string             `json:"root"`
+	Analytics        Analytics          `json:"analytics"`
+	Changes          *ChangeSummary     `json:"changes,omitempty"`
+	Redactions       []Redaction        `json:"redactions,omitempty"`
+	Incomplete       bool               `json:"incomplete,omitempty"`
+	IncompleteReason string             `json:"incomplete_reason,omitempty"`
+	Diagnostics      []Diagnostic       `json:"diagnostics,omitempty"`
+	Complexity       *ComplexityReport  `json:"complexity,omitempty"`
+	Duplicates       []DuplicateCluster `json:"duplicates,omitempty"`
+	Tests            *TestReport        `json:"tests,omitempty"`
+	Dependencies     []Manifest         `json:"dependencies,omitempty"`
+	Modules          []Module           `json:"modules,omitempty"`
+	EntryPoints      []EntryPoint       `json:"entry_points,omitempty"`
+	APISurface       []Endpoint         `json:"api_surface,omitempty"`
+	TechDebt         []FileAnnotation   `json:"tech_debt,omitempty"`
+	History          *HistoryReport     `json:"history,omitempty"`
+}
+
+// DiffElement locates a code element in one side of an AnalysisDiff.
+type DiffElement struct {
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Line      int    `json:"line,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// MovedElement is an element that disappeared from one file and appeared in another.
+type MovedElement struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	FromPath string `json:"from_path"`
+	ToPath   string `json:"to_path"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// SignatureChange is an element whose declaration changed in place.
+type SignatureChange struct {
+	Path         string `json:"path"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	Line         int    `json:"line,omitempty"`
+	OldSignature string `json:"old_signature"`
+	Signature    string `json:"signature"`
+}
+
+// LanguageDelta compares the statistics of one language between two analyses.
+type LanguageDelta struct {
+	OldFiles int `json:"old_files"`
+	NewFiles int `json:"new_files"`
+	OldLOC   int `json:"old_lines_of_code"`
+	NewLOC   int `json:"new_lines_of_code"`
+}
+
+// DependencyEdge is an import of Target by the file at Path.
+type DependencyEdge struct {
+	Path   string `json:"path"`
+	Target string `json:"target"`
+}
+
+// AnalysisDiff is the structural difference between two analyses. All paths
+// are relative to the root of their analysis.
+type AnalysisDiff struct {
+	OldRoot             string                   `json:"old_root"`
+	NewRoot             string                   `json:"new_root"`
+	FilesAdded          []string                 `json:"files_added,omitempty"`
+	FilesRemoved        []string                 `json:"files_removed,omitempty"`
+	ElementsAdded       []DiffElement            `json:"elements_added,omitempty"`
+	ElementsRemoved     []DiffElement            `json:"elements_removed,omitempty"`
+	ElementsMoved       []MovedElement           `json:"elements_moved,omitempty"`
+	SignatureChanges    []SignatureChange        `json:"signature_changes,omitempty"`
+	LanguageDeltas      map[string]LanguageDelta `json:"language_deltas,omitempty"`
+	DependenciesAdded   []DependencyEdge         `json:"dependencies_added,omitempty"`
+	DependenciesRemoved []DependencyEdge         `json:"dependencies_removed,omitempty"`
 }
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Tagged comment lines. Tags are only recognized at the start of a line of
+// a comment, in capitals, so prose such as "a hack" is left alone; the
+// owner in TODO(owner) is optional. Deprecation is written "Deprecated:"
+// in Go and "@deprecated" in JSDoc and Javadoc.
+var (
+	annotationTag  = regexp.MustCompile(`^(TODO|FIXME|HACK|XXX)\b(?:\(([^)]*)\))?[\s:!-]*(.*)$`)
+	deprecationTag = regexp.MustCompile(`^(?:Deprecated:|@deprecated\b|DEPRECATED\b)[\s:-]*(.*)$`)
+)
+
+// commentMarkers are stripped from the start of each comment line, longest
+// first.
+var commentMarkers = []string{"<!--", "/**", "/*!", "///", "//!", "/*", "//", "#", "*"}
+
+// elementSpan is the byte range of the declaration of a code element.
+type elementSpan struct {
+	start, end uint32
+	startRow   uint32
+	name       string
+}
+
+// extractAnnotations returns the tagged comments below root, each with the
+// element it is in or, for a comment right above a declaration, documents.
+func extractAnnotations(root *sitter.Node, content []byte, query *sitter.Query, elements []elementSpan) []model.Annotation {
+	if query == nil {
+		return nil
+	}
+	var comments []*sitter.Node
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, root)
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		for _, capture := range match.Captures {
+			comments = append(comments, capture.Node)
+		}
+	}
+
+	// A doc comment written as consecutive line comments documents the
+	// declaration below its last line.
+	blockEnd := make([]uint32, len(comments))
+	for i := len(comments) - 1; i >= 0; i-- {
+		blockEnd[i] = comments[i].EndPoint().Row
+		if i+1 < len(comments) && comments[i+1].StartPoint().Row == blockEnd[i]+1 {
+			blockEnd[i] = blockEnd[i+1]
+		}
+	}
+
+	var annotations []model.Annotation
+	for i, comment := range comments {
+		element := enclosingElement(elements, comment, blockEnd[i])
+		for offset, line := range strings.Split(comment.Content(content), "\n") {
+			annotation, ok := parseAnnotation(line)
+			if !ok {
+				continue
+			}
+			annotation.Line = int(comment.StartPoint().Row) + offset + 1
+			annotation.Element = element
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// parseAnnotation reads the tag, owner and text of a line of a comment.
+func parseAnnotation(line string) (model.Annotation, bool) {
+	line = strings.TrimSpace(line)
+	for _, marker := range commentMarkers {
+		if rest, ok := strings.CutPrefix(line, marker); ok {
+			line = strings.TrimSpace(rest)
+			break
+		}
+	}
+	line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(line, "*/"), "-->"))
+	if m := annotationTag.FindStringSubmatch(line); m != nil {
+		return model.Annotation{Tag: m[1], Owner: strings.TrimSpace(m[2]), Text: strings.TrimSpace(m[3])}, true
+	}
+	if m := deprecationTag.FindStringSubmatch(line); m != nil {
+		return model.Annotation{Tag: "DEPRECATED", Text: strings.TrimSpace(m[1])}, true
+	}
+	return model.Annotation{}, false
+}
+
+// enclosingElement returns the name of the element declared on the line
+// after the comment block ending on blockEnd, which the comment documents,
+// or else of the innermost element whose declaration holds the comment, or
+// "".
+func enclosingElement(elements []elementSpan, comment *sitter.Node, blockEnd uint32) string {
+	for _, el := range elements {
+		if el.startRow == blockEnd+1 {
+			return el.name
+		}
+	}
+	best := -1
+	for i, el := range elements {
+		if el.start <= comment.StartByte() && comment.EndByte() <= el.end &&
+			(best < 0 || el.end-el.start < elements[best].end-elements[best].start) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return elements[best].name
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/harsh-apk/groot/internal/model"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// compiledLanguage holds everything Parse needs for one language that is
+// expensive to build: the queries are compiled once per process and parsers
+// are pooled, so each file only pays for its own parse.
+type compiledLanguage struct {
+	tsLang *sitter.Language
+	// elements merges every element query into one, so a file is matched in
+	// a single pass; elementTypes maps its pattern indices to element types.
+	elements     *sitter.Query
+	elementTypes []string
+	// imports merges the import queries, whose patterns capture @path.
+	imports *sitter.Query
+	// routes merges the route queries, in order.
+	routes *sitter.Query
+	// comments captures the grammar's comment nodes, for line counts.
+	comments *sitter.Query
+	parsers  sync.Pool
+}
+
+// compiledLanguages caches compiled languages by name.
+var compiledLanguages = struct {
+	sync.Mutex
+	byName map[string]*compiledLanguage
+}{byName: make(map[string]*compiledLanguage)}
+
+// compiledFor returns the compiled form of lang, compiling it on first use.
+// It returns nil when no grammar is available for the language.
+func compiledFor(lang model.Language) (*compiledLanguage, error) {
+	compiledLanguages.Lock()
+	defer compiledLanguages.Unlock()
+	if compiled, ok := compiledLanguages.byName[lang.Name]; ok {
+		return compiled, nil
+	}
+	tsLang, found := grammarMap[lang.Name]
+	if !found {
+		compiledLanguages.byName[lang.Name] = nil
+		return nil, nil
+	}
+	compiled, err := compileLanguage(lang, tsLang)
+	if err != nil {
+		return nil, err
+	}
+	compiledLanguages.byName[lang.Name] = compiled
+	return compiled, nil
+}
+
+// compileLanguage compiles the element, import, route and comment queries
+// of lang.
+func compileLanguage(lang model.Language, tsLang *sitter.Language) (*compiledLanguage, error) {
+	compiled := &compiledLanguage{tsLang: tsLang}
+	compiled.parsers.New = func() any {
+		return compiled.newParser()
+	}
+
+	// Each query source may hold several patterns, so they are compiled on
+	// their own first to learn which pattern indices belong to which type.
+	var sources []string
+	for _, langQuery := range lang.Queries {
+		if langQuery.Query == "" {
+			continue
+		}
+		query, err := sitter.NewQuery([]byte(langQuery.Query), tsLang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile query for type '%s': %w", langQuery.Type, err)
+		}
+		for i := uint32(0); i < query.PatternCount(); i++ {
+			compiled.elementTypes = append(compiled.elementTypes, langQuery.Type)
+		}
+		query.Close()
+		sources = append(sources, langQuery.Query)
+	}
+	if len(sources) > 0 {
+		query, err := sitter.NewQuery([]byte(strings.Join(sources, "\n")), tsLang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile queries for %s: %w", lang.Name, err)
+		}
+		compiled.elements = query
+	}
+
+	if len(lang.ImportQueries) > 0 {
+		query, err := sitter.NewQuery([]byte(strings.Join(lang.ImportQueries, "\n")), tsLang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile import queries for %s: %w", lang.Name, err)
+		}
+		compiled.imports = query
+	}
+	if len(lang.RouteQueries) > 0 {
+		query, err := sitter.NewQuery([]byte(strings.Join(lang.RouteQueries, "\n")), tsLang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile route queries for %s: %w", lang.Name, err)
+		}
+		compiled.routes = query
+	}
+	compiled.comments = compileCommentQuery(tsLang)
+	return compiled, nil
+}
+
+// newParser creates a parser for the language.
+func (c *compiledLanguage) newParser() *sitter.Parser {
+	parser := sitter.NewParser()
+	parser.SetLanguage(c.tsLang)
+	return parser
+}
+
+// getParser takes a parser for the language from the pool.
+func (c *compiledLanguage) getParser() *sitter.Parser {
+	return c.parsers.Get().(*sitter.Parser)
+}
+
+// putParser returns a parser to the pool once its tree is no longer needed.
+func (c *compiledLanguage) putParser(parser *sitter.Parser) {
+	parser.Reset()
+	c.parsers.Put(parser)
+}
+
+// close frees the compiled queries and the pooled parsers. The language must
+// not be used afterwards.
+func (c *compiledLanguage) close() {
+	for _, query := range []*sitter.Query{c.elements, c.imports, c.routes, c.comments} {
+		if query != nil {
+			query.Close()
+		}
+	}
+	c.parsers.New = nil
+	for {
+		parser, ok := c.parsers.Get().(*sitter.Parser)
+		if !ok {
+			return
+		}
+		parser.Close()
+	}
+}
@@ -0,0 +1,232 @@
+package parser
+
+import sitter "github.com/smacker/go-tree-sitter"
+
+// complexityRules names the syntax node types that make a function harder to
+// follow in one language.
+type complexityRules struct {
+	// conditionals are if statements; their "alternative" child is an else
+	// branch or an else-if chain.
+	conditionals map[string]bool
+	// elseClauses wrap an else branch, e.g. JavaScript's else_clause.
+	elseClauses map[string]bool
+	// elifs are else-if clauses that have their own node type.
+	elifs map[string]bool
+	// branches are loops, catch clauses and conditional expressions: each
+	// adds a path and a level of nesting.
+	branches map[string]bool
+	// switches add a level of nesting; their cases add paths.
+	switches map[string]bool
+	cases    map[string]bool
+	// logical are the binary expressions whose operator is in logicalOps.
+	logical    map[string]bool
+	logicalOps map[string]bool
+}
+
+// complexityByLanguage holds the rules of each language that has them.
+var complexityByLanguage = map[string]complexityRules{
+	"Go": {
+		conditionals: set("if_statement"),
+		branches:     set("for_statement"),
+		switches:     set("expression_switch_statement", "type_switch_statement", "select_statement"),
+		cases:        set("expression_case", "type_case", "communication_case"),
+		logical:      set("binary_expression"),
+		logicalOps:   set("&&", "||"),
+	},
+	"JavaScript": {
+		conditionals: set("if_statement"),
+		elseClauses:  set("else_clause"),
+		branches: set("for_statement", "for_in_statement", "while_statement", "do_statement",
+			"catch_clause", "ternary_expression"),
+		switches:   set("switch_statement"),
+		cases:      set("switch_case"),
+		logical:    set("binary_expression"),
+		logicalOps: set("&&", "||", "??"),
+	},
+	"Java": {
+		conditionals: set("if_statement"),
+		branches: set("for_statement", "enhanced_for_statement", "while_statement", "do_statement",
+			"catch_clause", "ternary_expression"),
+		switches:   set("switch_expression"),
+		cases:      set("switch_label"),
+		logical:    set("binary_expression"),
+		logicalOps: set("&&", "||"),
+	},
+	"Python": {
+		conditionals: set("if_statement"),
+		elseClauses:  set("else_clause"),
+		elifs:        set("elif_clause"),
+		branches:     set("for_statement", "while_statement", "except_clause", "conditional_expression"),
+		switches:     set("match_statement"),
+		cases:        set("case_clause"),
+		logical:      set("boolean_operator"),
+		logicalOps:   set("and", "or"),
+	},
+	"Rust": {
+		conditionals: set("if_expression"),
+		elseClauses:  set("else_clause"),
+		branches:     set("for_expression", "while_expression", "loop_expression"),
+		switches:     set("match_expression"),
+		cases:        set("match_arm"),
+		logical:      set("binary_expression"),
+		logicalOps:   set("&&", "||"),
+	},
+}
+
+// set builds a lookup table of node types.
+func set(types ...string) map[string]bool {
+	m := make(map[string]bool, len(types))
+	for _, t := range types {
+		m[t] = true
+	}
+	return m
+}
+
+// complexityOf returns the cyclomatic and cognitive complexity of the
+// function named by nameNode, and false when the element is not a function
+// with a body or the language has no rules.
+//
+// Cyclomatic complexity is one plus the number of decision points. Cognitive
+// complexity follows the SonarSource definition: every break in the linear
+// flow costs one, plus one per enclosing level of nesting for structures
+// that nest; else and else-if branches and each run of a boolean operator
+// cost one.
+func complexityOf(nameNode *sitter.Node, langName string) (int, int, bool) {
+	rules, ok := complexityByLanguage[langName]
+	if !ok {
+		return 0, 0, false
+	}
+	body := functionBody(nameNode, langName)
+	if body == nil {
+		return 0, 0, false
+	}
+	c := &complexityCounter{rules: rules, functions: skeletonBodies[langName], cyclomatic: 1}
+	c.visit(body, 0)
+	return c.cyclomatic, c.cognitive, true
+}
+
+// functionBody returns the body of the function named by nameNode, looking
+// through variable declarators that hold a function, or nil when the element
+// is not a function with a body.
+func functionBody(nameNode *sitter.Node, langName string) *sitter.Node {
+	decl := nameNode.Parent()
+	if decl == nil {
+		return nil
+	}
+	fn := decl
+	if !skeletonBodies[langName][fn.Type()] {
+		if fn = decl.ChildByFieldName("value"); fn == nil || !skeletonBodies[langName][fn.Type()] {
+			return nil
+		}
+	}
+	return fn.ChildByFieldName("body")
+}
+
+// complexityCounter accumulates the complexity of one function body.
+type complexityCounter struct {
+	rules      complexityRules
+	functions  map[string]bool
+	cyclomatic int
+	cognitive  int
+}
+
+// visit scores n at the given nesting level and descends into its children.
+func (c *complexityCounter) visit(n *sitter.Node, nesting int) {
+	t := n.Type()
+	switch {
+	case c.rules.conditionals[t]:
+		c.cyclomatic++
+		if c.isElseIf(n) {
+			c.cognitive++
+		} else {
+			c.cognitive += 1 + nesting
+		}
+		c.visitConditional(n, nesting)
+		return
+	case c.rules.elifs[t]:
+		c.cyclomatic++
+		c.cognitive++
+	case c.rules.branches[t]:
+		c.cyclomatic++
+		c.cognitive += 1 + nesting
+	case c.rules.switches[t]:
+		c.cognitive += 1 + nesting
+	case c.rules.cases[t]:
+		if !isDefaultCase(n) {
+			c.cyclomatic++
+		}
+	case c.rules.logical[t]:
+		if op := c.logicalOp(n); op != "" {
+			c.cyclomatic++
+			if parent := n.Parent(); parent == nil || c.logicalOp(parent) != op {
+				c.cognitive++
+			}
+		}
+	}
+	if c.rules.branches[t] || c.rules.switches[t] || c.rules.elifs[t] || c.functions[t] {
+		nesting++
+	}
+	c.visitChildren(n, nesting)
+}
+
+// visitConditional descends into an if statement: its condition and
+// consequence are nested one level deeper, while an else-if chain continues
+// at the level of the if itself.
+func (c *complexityCounter) visitConditional(n *sitter.Node, nesting int) {
+	for i := 0; i < int(n.ChildCount()); i++ {
+		child := n.Child(i)
+		if n.FieldNameForChild(i) != "alternative" {
+			c.visit(child, nesting+1)
+			continue
+		}
+		switch {
+		case c.rules.conditionals[child.Type()], c.rules.elifs[child.Type()]:
+			c.visit(child, nesting)
+		case c.rules.elseClauses[child.Type()] && child.NamedChildCount() == 1 && c.rules.conditionals[child.NamedChild(0).Type()]:
+			c.visit(child.NamedChild(0), nesting)
+		default:
+			// A plain else branch.
+			c.cognitive++
+			c.visit(child, nesting+1)
+		}
+	}
+}
+
+// visitChildren visits every child of n.
+func (c *complexityCounter) visitChildren(n *sitter.Node, nesting int) {
+	for i := 0; i < int(n.ChildCount()); i++ {
+		c.visit(n.Child(i), nesting)
+	}
+}
+
+// isElseIf reports whether the conditional n is the alternative of another.
+func (c *complexityCounter) isElseIf(n *sitter.Node) bool {
+	parent := n.Parent()
+	if parent == nil {
+		return false
+	}
+	if c.rules.elseClauses[parent.Type()] {
+		return true
+	}
+	return c.rules.conditionals[parent.Type()] && parent.ChildByFieldName("alternative") != nil &&
+		parent.ChildByFieldName("alternative").Equal(n)
+}
+
+// logicalOp returns the boolean operator of n, or "" when n is not a boolean
+// operation.
+func (c *complexityCounter) logicalOp(n *sitter.Node) string {
+	if !c.rules.logical[n.Type()] {
+		return ""
+	}
+	op := n.ChildByFieldName("operator")
+	if op == nil || !c.rules.logicalOps[op.Type()] {
+		return ""
+	}
+	return op.Type()
+}
+
+// isDefaultCase reports whether a case node is the default branch, which
+// adds no path of its own.
+func isDefaultCase(n *sitter.Node) bool {
+	return n.ChildCount() > 0 && n.Child(0).Type() == "default"
+}
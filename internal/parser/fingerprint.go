@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Winnowing parameters: hashes are taken over runs of kgramLen tokens, and
+// the smallest hash of every window of winnowWindow consecutive runs is kept,
+// so any shared run of kgramLen+winnowWindow-1 tokens yields a shared hash.
+const (
+	kgramLen     = 8
+	winnowWindow = 4
+)
+
+// minFingerprintTokens is the size below which function bodies are not
+// fingerprinted: short helpers and accessors look alike without being copies.
+const minFingerprintTokens = 40
+
+// fingerprintOf returns the fingerprint of the body of a function, or nil
+// when it is too short to be worth comparing.
+func fingerprintOf(body *sitter.Node) *model.BodyFingerprint {
+	var tokens []uint64
+	collectTokens(body, &tokens)
+	if len(tokens) < minFingerprintTokens {
+		return nil
+	}
+	return &model.BodyFingerprint{
+		Hash:    foldHashes(tokens),
+		Tokens:  len(tokens),
+		EndLine: int(body.EndPoint().Row + 1),
+		Winnow:  winnow(kgrams(tokens)),
+	}
+}
+
+// collectTokens appends a hash of every token below n, normalized so that
+// renaming identifiers or changing literals does not change the stream.
+// Comments are left out.
+func collectTokens(n *sitter.Node, tokens *[]uint64) {
+	if n.ChildCount() == 0 {
+		if token := normalizedToken(n); token != "" {
+			*tokens = append(*tokens, hashString(token))
+		}
+		return
+	}
+	if strings.Contains(n.Type(), "comment") {
+		return
+	}
+	for i := 0; i < int(n.ChildCount()); i++ {
+		collectTokens(n.Child(i), tokens)
+	}
+}
+
+// normalizedToken maps a leaf node to its token: keywords and punctuation are
+// kept, identifiers and literals are replaced by a placeholder.
+func normalizedToken(n *sitter.Node) string {
+	t := n.Type()
+	if !n.IsNamed() {
+		return t
+	}
+	switch {
+	case strings.Contains(t, "comment"):
+		return ""
+	case strings.Contains(t, "identifier"):
+		return "$id"
+	case strings.Contains(t, "string"), strings.Contains(t, "literal"), strings.Contains(t, "number"),
+		strings.Contains(t, "integer"), strings.Contains(t, "float"), t == "true", t == "false":
+		return "$lit"
+	}
+	return t
+}
+
+// hashString returns the 64-bit FNV-1a hash of s.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// kgrams hashes every run of kgramLen consecutive tokens.
+func kgrams(tokens []uint64) []uint64 {
+	if len(tokens) < kgramLen {
+		return nil
+	}
+	hashes := make([]uint64, 0, len(tokens)-kgramLen+1)
+	for i := 0; i+kgramLen <= len(tokens); i++ {
+		hashes = append(hashes, foldHashes(tokens[i:i+kgramLen]))
+	}
+	return hashes
+}
+
+// foldHashes combines a sequence of hashes into one, FNV-style.
+func foldHashes(hashes []uint64) uint64 {
+	var h uint64 = 14695981039346656037
+	for _, t := range hashes {
+		h = (h ^ t) * 1099511628211
+	}
+	return h
+}
+
+// winnow selects the smallest hash of each window, keeping each distinct
+// hash once (Schleimer, Wilkerson and Aiken, 2003).
+func winnow(hashes []uint64) []uint64 {
+	seen := make(map[uint64]bool)
+	var selected []uint64
+	for start := 0; start < len(hashes); start++ {
+		end := start + winnowWindow
+		if end > len(hashes) {
+			if start > 0 {
+				break
+			}
+			end = len(hashes)
+		}
+		min := hashes[start]
+		for _, h := range hashes[start+1 : end] {
+			if h < min {
+				min = h
+			}
+		}
+		if !seen[min] {
+			seen[min] = true
+			selected = append(selected, min)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+func TestWinnow(t *testing.T) {
+	tests := []struct {
+		name   string
+		hashes []uint64
+		want   []uint64
+	}{
+		{"empty", nil, nil},
+		{"shorter than a window", []uint64{5, 3}, []uint64{3}},
+		{"one minimum for every window", []uint64{4, 2, 7, 1, 9, 3}, []uint64{1}},
+		{"minimum of each window", []uint64{9, 8, 7, 6, 5, 4, 3}, []uint64{6, 5, 4, 3}},
+		{"repeated minimum kept once", []uint64{1, 5, 5, 5, 5, 1}, []uint64{1, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := winnow(tt.hashes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("winnow(%v) = %v, want %v", tt.hashes, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWinnowSharedRun checks the winnowing guarantee: token streams sharing
+// a run of kgramLen+winnowWindow-1 tokens share at least one hash.
+func TestWinnowSharedRun(t *testing.T) {
+	tokens := func(start, n uint64) []uint64 {
+		var out []uint64
+		for i := uint64(0); i < n; i++ {
+			out = append(out, hashString(string(rune('a'+(start+i*7)%26))))
+		}
+		return out
+	}
+	shared := tokens(100, kgramLen+winnowWindow-1)
+	a := append(append(tokens(1, 30), shared...), tokens(2, 30)...)
+	b := append(append(tokens(3, 17), shared...), tokens(5, 41)...)
+
+	inA := make(map[uint64]bool)
+	for _, h := range winnow(kgrams(a)) {
+		inA[h] = true
+	}
+	for _, h := range winnow(kgrams(b)) {
+		if inA[h] {
+			return
+		}
+	}
+	t.Error("streams sharing a full window of k-grams have no winnowing hash in common")
+}
+
+func TestFingerprintIgnoresNamesAndLiterals(t *testing.T) {
+	lang := model.Language{
+		Name:    "Go",
+		Queries: []model.LanguageQuery{{Type: "Function", Query: `(function_declaration name: (identifier) @name)`}},
+	}
+	source := []byte(`package p
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		if v > 10 {
+			total += v * 2
+		} else {
+			total += v
+		}
+		if total > 1000 {
+			return 1
+		}
+	}
+	return total
+}
+
+func add(xs []int) int {
+	acc := 1
+	for _, x := range xs {
+		if x > 99 {
+			acc += x * 3
+		} else {
+			acc += x
+		}
+		if acc > 5 {
+			return 0
+		}
+	}
+	return acc
+}
+
+func short() int { return 1 }
+`)
+	result, err := Parse(context.Background(), source, lang, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prints := make(map[string]*model.BodyFingerprint)
+	for _, el := range result.Elements {
+		prints[el.Name] = el.Fingerprint
+	}
+	if prints["sum"] == nil || prints["add"] == nil {
+		t.Fatalf("fingerprints = %v, want sum and add fingerprinted", prints)
+	}
+	if prints["sum"].Hash != prints["add"].Hash || !reflect.DeepEqual(prints["sum"].Winnow, prints["add"].Winnow) {
+		t.Error("renaming identifiers and changing literals changed the fingerprint")
+	}
+	if prints["short"] != nil {
+		t.Errorf("short function fingerprinted with %d tokens", prints["short"].Tokens)
+	}
+}
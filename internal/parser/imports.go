@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// extractImports runs the language's import query and returns the distinct
+// imported modules or paths, sorted, with any surrounding quotes removed.
+func extractImports(root *sitter.Node, content []byte, query *sitter.Query) []string {
+	if query == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var imports []string
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, root)
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		match = qc.FilterPredicates(match, content)
+		for _, capture := range match.Captures {
+			if query.CaptureNameForId(capture.Index) != "path" {
+				continue
+			}
+			path := strings.Trim(capture.Node.Content(content), "\"'`")
+			if path != "" && !seen[path] {
+				seen[path] = true
+				imports = append(imports, path)
+			}
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
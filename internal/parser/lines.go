@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// commentNodeTypes are the node types grammars use for comments. Each
+// grammar defines some of them; the others are left out of its query.
+var commentNodeTypes = []string{"comment", "line_comment", "block_comment"}
+
+// span is a half-open byte range of the source.
+type span struct {
+	start, end uint32
+}
+
+// compileCommentQuery builds a query capturing every comment node of
+// tsLang, or returns nil when the grammar has no comment node.
+func compileCommentQuery(tsLang *sitter.Language) *sitter.Query {
+	var patterns []string
+	for _, nodeType := range commentNodeTypes {
+		pattern := "(" + nodeType + ") @comment"
+		if query, err := sitter.NewQuery([]byte(pattern), tsLang); err == nil {
+			query.Close()
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	query, err := sitter.NewQuery([]byte(strings.Join(patterns, "\n")), tsLang)
+	if err != nil {
+		return nil
+	}
+	return query
+}
+
+// commentSpans returns the byte ranges of the comments below root, in
+// source order.
+func commentSpans(root *sitter.Node, query *sitter.Query) []span {
+	if query == nil {
+		return nil
+	}
+	var spans []span
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, root)
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		for _, capture := range match.Captures {
+			spans = append(spans, span{capture.Node.StartByte(), capture.Node.EndByte()})
+		}
+	}
+	return spans
+}
+
+// CountLines classifies each line of content without a parse tree, so
+// comment lines count as code. It serves files that could not be parsed.
+func CountLines(content []byte) model.LineCounts {
+	return countLines(content, nil)
+}
+
+// countLines classifies each line of content given the comment spans. A
+// final line without a newline counts; an empty file has no lines.
+func countLines(content []byte, comments []span) model.LineCounts {
+	var counts model.LineCounts
+	next := 0
+	for lineStart := 0; lineStart < len(content); {
+		lineEnd := lineStart
+		for lineEnd < len(content) && content[lineEnd] != '\n' {
+			lineEnd++
+		}
+		hasCode, inComment := false, false
+		for pos := lineStart; pos < lineEnd; pos++ {
+			for next < len(comments) && int(comments[next].end) <= pos {
+				next++
+			}
+			commented := next < len(comments) && int(comments[next].start) <= pos
+			inComment = inComment || commented
+			if !commented && !isSpace(content[pos]) {
+				hasCode = true
+			}
+		}
+		switch {
+		case hasCode:
+			counts.Code++
+		case inComment:
+			counts.Comment++
+		default:
+			counts.Blank++
+		}
+		lineStart = lineEnd + 1
+	}
+	return counts
+}
+
+// isSpace reports whether b is ASCII whitespace.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\v' || b == '\f'
+}
@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/model"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// httpMethods are the methods routes are reported with, besides ANY.
+var httpMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
+}
+
+// routePrefix is a path that applies to the routes inside a node, such as
+// the class-level @RequestMapping of a Spring controller.
+type routePrefix struct {
+	start, end uint32
+	path       string
+}
+
+// routeGroup is a variable holding a router that adds a prefix to the
+// routes registered on it, such as the v1 of v1 := r.Group("/v1") in gin.
+// receiver is the router the group was made from.
+type routeGroup struct {
+	name     string
+	receiver string
+	start    uint32
+	path     string
+}
+
+// extractRoutes runs the language's route queries and returns the routes
+// they define, by line, with the prefixes of their scopes and groups.
+func extractRoutes(root *sitter.Node, content []byte, query *sitter.Query) []model.Route {
+	if query == nil {
+		return nil
+	}
+	var prefixes []routePrefix
+	var groups []routeGroup
+	routers := make(map[string]bool)
+	// Routes are grouped by the node that defines them, keeping those of
+	// the first pattern that matched it.
+	type found struct {
+		pattern  uint16
+		start    uint32
+		receiver string
+		route    model.Route
+	}
+	var candidates []found
+	firstPattern := make(map[uint32]uint16)
+
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, root)
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		match = qc.FilterPredicates(match, content)
+		if len(match.Captures) == 0 {
+			continue
+		}
+		var node, scope, group *sitter.Node
+		var route model.Route
+		prefix, method, methods, receiver := "", "", "", ""
+		for _, capture := range match.Captures {
+			text := capture.Node.Content(content)
+			switch query.CaptureNameForId(capture.Index) {
+			case "route":
+				node = capture.Node
+			case "path":
+				route.Path = text
+			case "method":
+				method = text
+			case "methods":
+				methods = text
+			case "handler":
+				route.Handler = handlerName(text)
+			case "prefix":
+				prefix = text
+			case "scope":
+				scope = capture.Node
+			case "group":
+				group = capture.Node
+			case "receiver":
+				receiver = text
+			case "router":
+				routers[text] = true
+			}
+		}
+		if scope != nil {
+			prefixes = append(prefixes, routePrefix{start: scope.StartByte(), end: scope.EndByte(), path: prefix})
+			continue
+		}
+		if group != nil {
+			groups = append(groups, routeGroup{name: group.Content(content), receiver: receiver, start: group.StartByte(), path: prefix})
+			continue
+		}
+		if node == nil {
+			continue
+		}
+		// A prefix captured with a route belongs to a chained group, as in
+		// r.Group("/api").GET("/ping", ping).
+		if prefix != "" {
+			route.Path = joinRoutePath(prefix, route.Path)
+		}
+		route.Method = routeMethod(method)
+		if methods != "" {
+			route.Method = routeMethod(methods)
+		}
+		// Go 1.22 patterns may start with the method: "GET /items/{id}".
+		if m, path, ok := strings.Cut(route.Path, " "); ok && httpMethods[m] {
+			route.Method, route.Path = m, strings.TrimSpace(path)
+		}
+		route.Line = int(node.StartPoint().Row + 1)
+		start := node.StartByte()
+		if first, seen := firstPattern[start]; !seen || match.PatternIndex < first {
+			firstPattern[start] = match.PatternIndex
+		}
+		candidates = append(candidates, found{pattern: match.PatternIndex, start: start, receiver: receiver, route: route})
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].start < groups[j].start })
+	var routes []model.Route
+	seen := make(map[model.Route]bool)
+	for _, c := range candidates {
+		if c.pattern != firstPattern[c.start] {
+			continue
+		}
+		if len(routers) > 0 && !routers[c.receiver] {
+			continue
+		}
+		route := c.route
+		route.Path = joinRoutePath(routeScopePrefix(prefixes, groups, c.receiver, c.start), route.Path)
+		if !seen[route] {
+			seen[route] = true
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].Line < routes[j].Line })
+	return routes
+}
+
+// routeMethod normalizes how a method is written, e.g. get, GetMapping or
+// RequestMethod.POST, to an HTTP method. Flask and FastAPI routes default
+// to GET; registrations for every method, such as http.HandleFunc or a
+// @RequestMapping without a method, are ANY.
+func routeMethod(name string) string {
+	upper := strings.ToUpper(strings.TrimSuffix(name, "Mapping"))
+	switch {
+	case httpMethods[upper]:
+		return upper
+	case upper == "ROUTE" || upper == "API_ROUTE":
+		return "GET"
+	case upper == "WEBSOCKET":
+		return "WS"
+	}
+	return "ANY"
+}
+
+// inlineFunction matches the keywords that start a function defined in
+// place, but not names such as asyncHandler that start with them.
+var inlineFunction = regexp.MustCompile(`^(func|function|async|lambda)\b`)
+
+// handlerName returns how a handler is written, or (inline) for a function
+// defined in place.
+func handlerName(text string) string {
+	if strings.ContainsAny(text, "\n{") || strings.Contains(text, "=>") ||
+		inlineFunction.MatchString(text) || strings.HasPrefix(text, "(") {
+		return "(inline)"
+	}
+	return text
+}
+
+// scopePrefix returns the path prefixes of the scopes holding the byte
+// offset joined from the outermost in, as for nested chi subrouters, or "".
+func scopePrefix(prefixes []routePrefix, offset uint32) string {
+	var enclosing []routePrefix
+	for _, p := range prefixes {
+		if p.start <= offset && offset < p.end {
+			enclosing = append(enclosing, p)
+		}
+	}
+	sort.Slice(enclosing, func(i, j int) bool { return enclosing[i].start < enclosing[j].start })
+	prefix := ""
+	for _, p := range enclosing {
+		prefix = joinRoutePath(prefix, p.path)
+	}
+	return prefix
+}
+
+// routeScopePrefix returns the path prefix of a route registered at the byte
+// offset on receiver: that of the group the receiver holds, or else that of
+// the scopes holding it. A group is the last one of its name defined before
+// the offset, and adds its own path to the prefix of where it was made.
+func routeScopePrefix(prefixes []routePrefix, groups []routeGroup, receiver string, offset uint32) string {
+	for i := len(groups) - 1; i >= 0; i-- {
+		g := groups[i]
+		if receiver != "" && g.name == receiver && g.start < offset {
+			return joinRoutePath(routeScopePrefix(prefixes, groups, g.receiver, g.start), g.path)
+		}
+	}
+	return scopePrefix(prefixes, offset)
+}
+
+// joinRoutePath joins a prefix and a route path with a single slash, and
+// makes the result absolute.
+func joinRoutePath(prefix, path string) string {
+	joined := path
+	if prefix != "" {
+		joined = strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+		if path == "" {
+			joined = prefix
+		}
+	}
+	if !strings.HasPrefix(joined, "/") {
+		joined = "/" + joined
+	}
+	return joined
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxSignatureLength caps signatures of declarations that have no body, such
+// as type definitions, so a large struct does not bloat the output.
+const maxSignatureLength = 200
+
+// signatureOf returns the declaration text of the element named by nameNode,
+// up to but excluding its body, with whitespace collapsed.
+func signatureOf(nameNode *sitter.Node, content []byte) string {
+	decl := nameNode.Parent()
+	if decl == nil {
+		return ""
+	}
+
+	end := decl.EndByte()
+	if body := declarationBody(decl); body != nil {
+		end = body.StartByte()
+	}
+	signature := strings.Join(strings.Fields(string(content[decl.StartByte():end])), " ")
+	signature = strings.TrimSpace(strings.TrimSuffix(signature, "{"))
+	if runes := []rune(signature); len(runes) > maxSignatureLength {
+		signature = strings.TrimSpace(string(runes[:maxSignatureLength])) + " ..."
+	}
+	return signature
+}
+
+// declarationBody returns the body of a declaration, looking through variable
+// declarators that hold a function, e.g. `const Foo = () => { ... }`.
+func declarationBody(decl *sitter.Node) *sitter.Node {
+	if body := decl.ChildByFieldName("body"); body != nil {
+		return body
+	}
+	if value := decl.ChildByFieldName("value"); value != nil {
+		return value.ChildByFieldName("body")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// skeletonBodies maps a language name to the syntax node types whose "body"
+// field is elided when building a skeleton. Languages without an entry have
+// no skeleton.
+var skeletonBodies = map[string]map[string]bool{
+	"Go": {
+		"function_declaration": true,
+		"method_declaration":   true,
+		"func_literal":         true,
+	},
+	"JavaScript": {
+		"function_declaration":           true,
+		"generator_function_declaration": true,
+		"function_expression":            true,
+		"generator_function":             true,
+		"arrow_function":                 true,
+		"method_definition":              true,
+	},
+	"Java": {
+		"method_declaration":      true,
+		"constructor_declaration": true,
+	},
+	"Python": {
+		"function_definition": true,
+	},
+	"Rust": {
+		"function_item": true,
+	},
+}
+
+// bodyEdit replaces the bytes in [start, end) with text.
+type bodyEdit struct {
+	start, end uint32
+	text       string
+}
+
+// buildSkeleton returns the source with every function and method body
+// replaced by a placeholder, keeping imports, types, signatures and comments.
+// It returns an empty string for languages that have no skeleton rules.
+func buildSkeleton(root *sitter.Node, content []byte, langName string) string {
+	bodyTypes, ok := skeletonBodies[langName]
+	if !ok {
+		return ""
+	}
+
+	var edits []bodyEdit
+	collectBodyEdits(root, bodyTypes, langName == "Python", &edits)
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var b strings.Builder
+	b.Grow(len(content))
+	var last uint32
+	for _, e := range edits {
+		if e.start < last {
+			continue
+		}
+		b.Write(content[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.Write(content[last:])
+	return b.String()
+}
+
+// collectBodyEdits walks the syntax tree and records an edit for every body
+// that should be elided. Elided bodies are not descended into, so nested
+// functions disappear together with their enclosing body.
+func collectBodyEdits(node *sitter.Node, bodyTypes map[string]bool, python bool, edits *[]bodyEdit) {
+	if bodyTypes[node.Type()] {
+		if body := node.ChildByFieldName("body"); body != nil {
+			if edit, ok := elideBody(body, python); ok {
+				*edits = append(*edits, edit)
+			}
+			return
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		collectBodyEdits(node.NamedChild(i), bodyTypes, python, edits)
+	}
+}
+
+// elideBody builds the edit for a single body node.
+func elideBody(body *sitter.Node, python bool) (bodyEdit, bool) {
+	if !python {
+		text := "..."
+		if body.Type() == "block" || body.Type() == "statement_block" || body.Type() == "constructor_body" {
+			text = "{ ... }"
+		}
+		return bodyEdit{start: body.StartByte(), end: body.EndByte(), text: text}, true
+	}
+
+	// Python bodies are indented blocks; keep a leading docstring and replace
+	// the remaining statements with an ellipsis at the same indentation.
+	edit := bodyEdit{start: body.StartByte(), end: body.EndByte(), text: "..."}
+	if body.NamedChildCount() == 0 {
+		return edit, true
+	}
+	first := body.NamedChild(0)
+	if first.Type() == "expression_statement" && first.NamedChildCount() > 0 && first.NamedChild(0).Type() == "string" {
+		if body.NamedChildCount() == 1 {
+			return bodyEdit{}, false
+		}
+		indent := strings.Repeat(" ", int(body.StartPoint().Column))
+		edit.start = first.EndByte()
+		edit.text = "\n" + indent + "..."
+	}
+	return edit, true
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"github.com/harsh-apk/groot/internal/model"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// MaxSyntaxErrors caps how many syntax errors are collected per file.
+const MaxSyntaxErrors = 20
+
+// findSyntaxErrors collects the outermost ERROR and MISSING nodes below root,
+// in source order.
+func findSyntaxErrors(root *sitter.Node) []model.SyntaxError {
+	var found []model.SyntaxError
+	var visit func(n *sitter.Node)
+	visit = func(n *sitter.Node) {
+		if len(found) >= MaxSyntaxErrors {
+			return
+		}
+		if n.IsMissing() || n.IsError() {
+			point := n.StartPoint()
+			found = append(found, model.SyntaxError{
+				Line:    int(point.Row + 1),
+				Column:  int(point.Column + 1),
+				Missing: n.IsMissing(),
+				Token:   n.Type(),
+			})
+			return
+		}
+		if !n.HasError() {
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			visit(n.Child(i))
+		}
+	}
+	visit(root)
+	return found
+}
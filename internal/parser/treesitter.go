@@ -2,6 +2,7 @@ package parser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/harsh-apk/groot/internal/model"
@@ -14,7 +15,9 @@ import (
 	tree_sitter_java "github.com/tree-sitter/tree-sitter-java/bindings/go"
 	tree_sitter_javascript "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
 	tree_sitter_python "github.com/tree-sitter/tree-sitter-python/bindings/go"
-	tree_sitter_rust "github.com/tree-sitter/tree-sitter-rust/bindings/go"
+	// The upstream Rust grammar is generated for a newer Tree-sitter ABI
+	// than this runtime supports, so its queries would not compile.
+	"github.com/smacker/go-tree-sitter/rust"
 )
 
 // grammarMap maps a language name (from the YAML config) to its statically
@@ -23,7 +26,7 @@ var grammarMap = map[string]*sitter.Language{
 	"Go":     sitter.NewLanguage(tree_sitter_go.Language()),
 	"Python": sitter.NewLanguage(tree_sitter_python.Language()),
 	"Java":   sitter.NewLanguage(tree_sitter_java.Language()),
-	"Rust":   sitter.NewLanguage(tree_sitter_rust.Language()),
+	"Rust":   rust.GetLanguage(),
 	"CSS":    sitter.NewLanguage(tree_sitter_css.Language()),
 	"HTML":   sitter.NewLanguage(tree_sitter_html.Language()),
 
@@ -33,62 +36,110 @@ var grammarMap = map[string]*sitter.Language{
 	"JavaScript": sitter.NewLanguage(tree_sitter_javascript.Language()),
 }
 
-// Parse uses Tree-sitter to extract code elements from source code.
-func Parse(content []byte, lang model.Language) ([]model.CodeElement, error) {
-	// 1. Look up the grammar from our pre-populated map.
-	tsLang, found := grammarMap[lang.Name]
-	if !found {
+// Options selects the optional outputs that Parse produces in addition to the
+// code elements.
+type Options struct {
+	// Skeleton requests a copy of the source with function bodies elided.
+	Skeleton bool
+}
+
+// Result holds everything extracted from a single parse of a source file.
+type Result struct {
+	Elements []model.CodeElement
+	Imports  []string
+	Skeleton string
+	// SyntaxErrors lists where the source could not be parsed cleanly.
+	SyntaxErrors []model.SyntaxError
+	Lines        model.LineCounts
+	// Routes lists the HTTP endpoints the file defines.
+	Routes []model.Route
+	// Annotations lists the TODO, FIXME, HACK, XXX and deprecation comments.
+	Annotations []model.Annotation
+}
+
+// Parse uses Tree-sitter to extract code elements from source code. It stops
+// early and returns ctx's error when ctx is done.
+func Parse(ctx context.Context, content []byte, lang model.Language, opts Options) (*Result, error) {
+	// 1. Look up the compiled grammar and queries, built once per language.
+	compiled, err := compiledFor(lang)
+	if err != nil {
+		return nil, err
+	}
+	if compiled == nil {
 		// Gracefully skip unsupported files instead of erroring.
-		return nil, nil
+		return &Result{Lines: countLines(content, nil)}, nil
 	}
 
-	// 2. Create a new Tree-sitter parser and set its language.
-	parser := sitter.NewParser()
-	parser.SetLanguage(tsLang)
-
-	// 3. Parse the source code content into a syntax tree.
-	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	// 2. Borrow a parser for the language and parse the source code content.
+	parser := compiled.getParser()
+	tree, err := parser.ParseCtx(ctx, nil, content)
+	if errors.Is(err, sitter.ErrOperationLimit) && ctx.Err() == nil {
+		// A cancellation that raced with the end of an earlier parse leaves
+		// the parser's cancellation flag set; a fresh parser does not have it.
+		parser.Close()
+		parser = compiled.newParser()
+		tree, err = parser.ParseCtx(ctx, nil, content)
+	}
 	if err != nil {
+		// The parser may still carry the cancellation, so it is not reused.
+		parser.Close()
 		return nil, fmt.Errorf("failed to parse content: %w", err)
 	}
-
-	var allElements []model.CodeElement
+	defer compiled.putParser(parser)
+	defer tree.Close()
 	rootNode := tree.RootNode()
 
-	// 4. Iterate over all queries defined for this language.
-	for _, langQuery := range lang.Queries {
-		if langQuery.Query == "" {
-			continue
-		}
-
-		query, err := sitter.NewQuery([]byte(langQuery.Query), tsLang)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compile query for type '%s': %w", langQuery.Type, err)
-		}
-
+	// 3. Run the merged element query; each match's pattern gives its type.
+	var allElements []model.CodeElement
+	var spans []elementSpan
+	if compiled.elements != nil {
 		qc := sitter.NewQueryCursor()
-		qc.Exec(query, rootNode)
-
-		// 5. Iterate over all the matches found by the query.
+		defer qc.Close()
+		qc.Exec(compiled.elements, rootNode)
 		for {
 			match, ok := qc.NextMatch()
 			if !ok {
 				break
 			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			match = qc.FilterPredicates(match, content)
 
 			for _, capture := range match.Captures {
-				if query.CaptureNameForId(capture.Index) == "name" {
-					allElements = append(allElements, model.CodeElement{
-						Name: capture.Node.Content(content),
-						Type: langQuery.Type,
-						Line: int(capture.Node.StartPoint().Row + 1),
-					})
+				if compiled.elements.CaptureNameForId(capture.Index) == "name" {
+					element := model.CodeElement{
+						Name:      capture.Node.Content(content),
+						Type:      compiled.elementTypes[match.PatternIndex],
+						Line:      int(capture.Node.StartPoint().Row + 1),
+						Signature: signatureOf(capture.Node, content),
+					}
+					if cyclomatic, cognitive, ok := complexityOf(capture.Node, lang.Name); ok {
+						element.Cyclomatic, element.Cognitive = cyclomatic, cognitive
+					}
+					if body := functionBody(capture.Node, lang.Name); body != nil {
+						element.Fingerprint = fingerprintOf(body)
+					}
+					allElements = append(allElements, element)
+					if decl := capture.Node.Parent(); decl != nil {
+						spans = append(spans, elementSpan{decl.StartByte(), decl.EndByte(), decl.StartPoint().Row, element.Name})
+					}
 					break
 				}
 			}
 		}
 	}
 
-	return allElements, nil
+	result := &Result{
+		Elements:     allElements,
+		Imports:      extractImports(rootNode, content, compiled.imports),
+		Routes:       extractRoutes(rootNode, content, compiled.routes),
+		Annotations:  extractAnnotations(rootNode, content, compiled.comments, spans),
+		SyntaxErrors: findSyntaxErrors(rootNode),
+		Lines:        countLines(content, commentSpans(rootNode, compiled.comments)),
+	}
+	if opts.Skeleton {
+		result.Skeleton = buildSkeleton(rootNode, content, lang.Name)
+	}
+	return result, nil
 }
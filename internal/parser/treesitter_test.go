@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// benchLanguages mirror a part of the analyzer's language configuration,
+// which this package cannot import.
+var benchLanguages = []struct {
+	lang model.Language
+	// file is read as the source when set.
+	file   string
+	source string
+}{
+	{
+		lang: model.Language{
+			Name: "Go",
+			Queries: []model.LanguageQuery{
+				{Type: "Function", Query: `(function_declaration name: (identifier) @name)`},
+				{Type: "Method", Query: `(method_declaration name: (field_identifier) @name)`},
+				{Type: "Interface", Query: `(type_spec name: (type_identifier) @name (interface_type))`},
+				{Type: "Struct", Query: `(type_spec name: (type_identifier) @name (struct_type))`},
+				{Type: "Entry Point", Query: `((source_file (package_clause (package_identifier) @pkg) (function_declaration name: (identifier) @name)) (#eq? @pkg "main") (#eq? @name "main"))`},
+			},
+			ImportQueries: []string{`(import_spec path: (interpreted_string_literal) @path)`},
+		},
+		file: "treesitter.go",
+	},
+	{
+		lang: model.Language{
+			Name: "Python",
+			Queries: []model.LanguageQuery{
+				{Type: "Class", Query: `(class_definition name: (identifier) @name)`},
+				{Type: "Function", Query: `(function_definition name: (identifier) @name)`},
+			},
+			ImportQueries: []string{
+				`(import_statement name: (dotted_name) @path)`,
+				`(import_from_statement module_name: (dotted_name) @path)`,
+			},
+		},
+		source: `import os
+from collections import defaultdict
+
+
+class Inventory:
+    """Counts items by kind."""
+
+    def __init__(self):
+        self.items = defaultdict(int)
+
+    def add(self, kind, count=1):
+        if count <= 0:
+            raise ValueError("count must be positive")
+        self.items[kind] += count
+
+    def report(self):
+        for kind, count in sorted(self.items.items()):
+            print(f"{kind}: {count}")
+
+
+def main():
+    inventory = Inventory()
+    for arg in os.environ.get("ITEMS", "").split(","):
+        if arg:
+            inventory.add(arg)
+    inventory.report()
+`,
+	},
+}
+
+// benchmarkParse parses each benchmark source once per iteration. When
+// perFile is set, the compiled queries and pooled parsers are dropped
+// before every parse, which is what each file used to pay for.
+func benchmarkParse(b *testing.B, perFile bool) {
+	for _, bench := range benchLanguages {
+		content := []byte(bench.source)
+		if bench.file != "" {
+			data, err := os.ReadFile(bench.file)
+			if err != nil {
+				b.Fatal(err)
+			}
+			content = data
+		}
+		b.Run(bench.lang.Name, func(b *testing.B) {
+			ctx := context.Background()
+			b.SetBytes(int64(len(content)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if perFile {
+					forgetCompiled(bench.lang.Name)
+				}
+				if _, err := Parse(ctx, content, bench.lang, Options{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// forgetCompiled frees and drops the compiled form of a language, so the
+// next Parse compiles its queries and creates a parser again.
+func forgetCompiled(name string) {
+	compiledLanguages.Lock()
+	if compiled := compiledLanguages.byName[name]; compiled != nil {
+		compiled.close()
+	}
+	delete(compiledLanguages.byName, name)
+	compiledLanguages.Unlock()
+}
+
+func BenchmarkParseCompilePerFile(b *testing.B) {
+	benchmarkParse(b, true)
+}
+
+func BenchmarkParseCompiledPooled(b *testing.B) {
+	benchmarkParse(b, false)
+}
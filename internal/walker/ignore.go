@@ -0,0 +1,318 @@
+package walker
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/harsh-apk/groot/internal/git"
+)
+
+// ignoreRule is a single parsed gitignore pattern.
+type ignoreRule struct {
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// ignoreList holds the rules read from one source. Rules only apply to paths
+// below base, which is slash-separated and relative to the matcher root.
+type ignoreList struct {
+	base  string
+	rules []ignoreRule
+}
+
+// newIgnoreList parses gitignore-formatted lines scoped to base.
+func newIgnoreList(base string, lines []string) *ignoreList {
+	list := &ignoreList{base: base}
+	for _, line := range lines {
+		if rule, ok := parseIgnoreRule(line); ok {
+			list.rules = append(list.rules, rule)
+		}
+	}
+	return list
+}
+
+// readIgnoreList loads a gitignore-formatted file. A missing file yields nil.
+func readIgnoreList(base, filePath string) *ignoreList {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return newIgnoreList(base, lines)
+}
+
+// match reports whether any rule matches relPath and, if so, whether the last
+// matching rule ignores it. Later rules take precedence, as in git.
+func (l *ignoreList) match(relPath string, isDir bool) (matched, ignored bool) {
+	if l == nil {
+		return false, false
+	}
+	sub := relPath
+	if l.base != "" {
+		var ok bool
+		sub, ok = strings.CutPrefix(relPath, l.base+"/")
+		if !ok {
+			return false, false
+		}
+	}
+	for i := len(l.rules) - 1; i >= 0; i-- {
+		rule := l.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(sub) {
+			return true, !rule.negate
+		}
+	}
+	return false, false
+}
+
+// parseIgnoreRule converts a gitignore line into a rule. It reports false for
+// blank lines and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to its own directory;
+	// otherwise it matches at any depth.
+	if strings.HasPrefix(line, "/") {
+		line = line[1:]
+	} else if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// trimTrailingSpaces removes unescaped trailing spaces.
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// globToRegexp translates a gitignore glob into a regular expression body.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// Leading or inner "**/" matches zero or more directories.
+			if i == 0 || glob[i-1] == '/' {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else {
+				b.WriteString("[^/]*")
+				i++
+			}
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob) && (i == 0 || glob[i-1] == '/'):
+			// A trailing "/**" matches everything inside the directory.
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Names of the per-directory pattern files consulted by the matcher.
+const (
+	gitIgnoreFile    = ".gitignore"
+	grootIgnoreFile  = ".grootignore"
+	grootIncludeFile = ".grootinclude"
+)
+
+// dirFile identifies a pattern file within a directory of the tree.
+type dirFile struct {
+	dir  string
+	name string
+}
+
+// ignoreMatcher decides whether a path is excluded, following git's rules:
+// patterns in deeper .gitignore files override shallower ones, which override
+// .git/info/exclude, which overrides the global core.excludesFile. Groot's
+// built-in defaults have the lowest precedence. On top of git's rules,
+// .grootignore files override every .gitignore, .grootinclude files force
+// matching paths back in, and the user's skip patterns have the final say.
+type ignoreMatcher struct {
+	// root is the directory that relative paths are resolved against: the
+	// repository root when analyzing inside a git repository.
+	root string
+	// base lists the repository-wide sources, lowest precedence first.
+	base []*ignoreList
+	// perDir caches the pattern files of each directory, loaded lazily.
+	perDir map[dirFile]*ignoreList
+	// override holds the user's skip patterns.
+	override *ignoreList
+	// hierarchy names the per-directory ignore files, highest precedence first.
+	hierarchy []string
+}
+
+// newIgnoreMatcher prepares a matcher for a walk starting at absRoot.
+func newIgnoreMatcher(ctx context.Context, absRoot string, customIgnorePatterns []string, opts Options) *ignoreMatcher {
+	m := &ignoreMatcher{
+		root:      absRoot,
+		perDir:    make(map[dirFile]*ignoreList),
+		hierarchy: []string{grootIgnoreFile, gitIgnoreFile},
+	}
+	repo, _ := git.FindRepo(absRoot)
+	if repo != nil {
+		m.root = repo.Root
+	}
+	// The user's patterns are scoped to the analyzed directory, not the repository.
+	overrideBase, _ := m.rel(absRoot)
+	m.override = newIgnoreList(overrideBase, customIgnorePatterns)
+
+	if !opts.NoDefaultIgnores {
+		m.base = append(m.base, newIgnoreList("", defaultIgnorePatterns))
+	}
+
+	if opts.GitTracked {
+		// The index already reflects git's ignore rules, so only groot's own
+		// patterns are layered on top of it.
+		m.hierarchy = []string{grootIgnoreFile}
+		return m
+	}
+
+	if repo != nil {
+		if excludesFile := git.ExcludesFile(ctx, repo.Root); excludesFile != "" {
+			m.base = append(m.base, readIgnoreList("", excludesFile))
+		}
+		m.base = append(m.base, readIgnoreList("", repo.InfoExcludePath()))
+	} else if excludesFile := git.ExcludesFile(ctx, ""); excludesFile != "" {
+		m.base = append(m.base, readIgnoreList("", excludesFile))
+	}
+	return m
+}
+
+// rel converts an absolute path into a slash-separated path relative to the
+// matcher root. It reports false for paths outside the root.
+func (m *ignoreMatcher) rel(absPath string) (string, bool) {
+	relPath, err := filepath.Rel(m.root, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if relPath == "." {
+		return "", true
+	}
+	return filepath.ToSlash(relPath), true
+}
+
+// dirList returns the rules of the pattern file name in directory dirRel,
+// loading them on first use.
+func (m *ignoreMatcher) dirList(dirRel, name string) *ignoreList {
+	key := dirFile{dir: dirRel, name: name}
+	list, ok := m.perDir[key]
+	if !ok {
+		list = readIgnoreList(dirRel, filepath.Join(m.root, filepath.FromSlash(dirRel), name))
+		m.perDir[key] = list
+	}
+	return list
+}
+
+// matchHierarchy consults the pattern file name in every directory from the
+// parent of relPath up to the root; the deepest file with a match decides.
+func (m *ignoreMatcher) matchHierarchy(name, relPath string, isDir bool) (matched, ignored bool) {
+	for dir := path.Dir(relPath); ; dir = path.Dir(dir) {
+		if dir == "." {
+			dir = ""
+		}
+		if matched, ignored := m.dirList(dir, name).match(relPath, isDir); matched {
+			return true, ignored
+		}
+		if dir == "" {
+			return false, false
+		}
+	}
+}
+
+// Ignored reports whether the path at absPath is excluded. It does not check
+// whether a parent directory is excluded; callers walking the tree prune
+// ignored directories instead.
+func (m *ignoreMatcher) Ignored(absPath string, isDir bool) bool {
+	relPath, ok := m.rel(absPath)
+	if !ok || relPath == "" {
+		return false
+	}
+	// Git's own metadata is never part of the working tree, whatever the patterns say.
+	if path.Base(relPath) == ".git" {
+		return true
+	}
+
+	if matched, ignored := m.override.match(relPath, isDir); matched {
+		return ignored
+	}
+	// A positive .grootinclude match wins over every ignore file; a negated
+	// one only withdraws the forced inclusion.
+	if matched, included := m.matchHierarchy(grootIncludeFile, relPath, isDir); matched && included {
+		return false
+	}
+	for _, name := range m.hierarchy {
+		if matched, ignored := m.matchHierarchy(name, relPath, isDir); matched {
+			return ignored
+		}
+	}
+	for i := len(m.base) - 1; i >= 0; i-- {
+		if matched, ignored := m.base[i].match(relPath, isDir); matched {
+			return ignored
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package walker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ignoreFixture lays out a repository with .gitignore files at several
+// depths. Paths ending in a slash are directories.
+var ignoreFixture = map[string]string{
+	".gitignore": `# comments and blank lines are skipped
+
+*.log
+!keep.log
+build/
+/root-only.txt
+docs/**/*.tmp
+**/cache
+a/**/z
+logs/**
+!logs/important/
+vendor
+\#hash
+*.bak
+*.[oa]
+tmp?.txt
+` + "trailing.txt   \n",
+	"sub/.gitignore": `!*.bak
+local.txt
+/anchored.txt
+nested/
+`,
+}
+
+// ignoreCases are the expected answers for the fixture, as git gives them.
+var ignoreCases = []struct {
+	path    string
+	ignored bool
+}{
+	// Unanchored patterns match at any depth; negation re-includes.
+	{"app.log", true},
+	{"keep.log", false},
+	{"sub/x/app.log", true},
+	{"sub/x/keep.log", false},
+	// Directory-only patterns skip files of the same name.
+	{"build/", true},
+	{"build/out.txt", true},
+	{"out/build", false},
+	{"lib/build/", true},
+	// A leading slash anchors a pattern to its directory.
+	{"root-only.txt", true},
+	{"sub/root-only.txt", false},
+	// ** matches zero or more directories.
+	{"docs/a.tmp", true},
+	{"docs/x/y/a.tmp", true},
+	{"other/a.tmp", false},
+	{"cache/", true},
+	{"x/y/cache/", true},
+	{"a/z", true},
+	{"a/b/c/z", true},
+	{"b/a/z", false},
+	// A trailing /** ignores the contents but not the directory, and a file
+	// cannot be re-included below an ignored directory.
+	{"logs/debug.txt", true},
+	{"logs/important/", false},
+	{"logs/important/x.txt", true},
+	// Patterns without a slash match files and directories.
+	{"vendor/", true},
+	{"src/vendor", true},
+	// Escapes, classes, ? and trailing spaces.
+	{"#hash", true},
+	{"x.o", true},
+	{"x.a", true},
+	{"x.c", false},
+	{"tmp1.txt", true},
+	{"tmp12.txt", false},
+	{"trailing.txt", true},
+	// Deeper .gitignore files override shallower ones for their subtree.
+	{"file.bak", true},
+	{"sub/file.bak", false},
+	{"sub/deep/file.bak", false},
+	{"local.txt", false},
+	{"sub/local.txt", true},
+	{"sub/deep/local.txt", true},
+	{"sub/anchored.txt", true},
+	{"sub/deep/anchored.txt", false},
+	{"sub/nested/", true},
+	{"sub/nested/f.go", true},
+	{"nested/f.go", false},
+}
+
+// writeIgnoreFixture creates the fixture files and the case paths below a
+// new git repository and returns its root.
+func writeIgnoreFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	// Keep the user's global excludes file out of the comparison.
+	t.Setenv("HOME", root)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, ".config"))
+	if err := exec.Command("git", "init", "-q", root).Run(); err != nil {
+		// Without git, a .git directory is enough for the matcher to find
+		// the repository root.
+		if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range ignoreFixture {
+		writeFixtureFile(t, filepath.Join(root, name), content)
+	}
+	for _, tc := range ignoreCases {
+		abs := filepath.Join(root, filepath.FromSlash(tc.path))
+		if strings.HasSuffix(tc.path, "/") {
+			if err := os.MkdirAll(abs, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		writeFixtureFile(t, abs, "")
+	}
+	return root
+}
+
+func writeFixtureFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := writeIgnoreFixture(t)
+	m := newIgnoreMatcher(context.Background(), root, nil, Options{NoDefaultIgnores: true})
+	parents := make(map[string]bool)
+	for _, tc := range ignoreCases {
+		isDir := strings.HasSuffix(tc.path, "/")
+		abs := filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(tc.path, "/")))
+		got := m.Ignored(abs, isDir) || parentIgnored(root, abs, m, parents)
+		if got != tc.ignored {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.path, got, tc.ignored)
+		}
+	}
+}
+
+// TestIgnoreMatcherAgreesWithGit checks the expected answers against git
+// itself, so the cases cannot drift from git's behavior.
+func TestIgnoreMatcherAgreesWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := writeIgnoreFixture(t)
+	for _, tc := range ignoreCases {
+		cmd := exec.Command("git", "-C", root, "check-ignore", "-q", "--no-index", strings.TrimSuffix(tc.path, "/"))
+		err := cmd.Run()
+		var exit *exec.ExitError
+		switch {
+		case err == nil:
+			if !tc.ignored {
+				t.Errorf("git check-ignore ignores %q, but the case expects it kept", tc.path)
+			}
+		case errors.As(err, &exit) && exit.ExitCode() == 1:
+			if tc.ignored {
+				t.Errorf("git check-ignore keeps %q, but the case expects it ignored", tc.path)
+			}
+		default:
+			t.Fatalf("git check-ignore %s: %v", tc.path, err)
+		}
+	}
+}
+
+func TestIgnoreMatcherPrecedence(t *testing.T) {
+	root := writeIgnoreFixture(t)
+	writeFixtureFile(t, filepath.Join(root, ".grootignore"), "keep.log\n")
+	writeFixtureFile(t, filepath.Join(root, ".grootinclude"), "app.log\n")
+	m := newIgnoreMatcher(context.Background(), root, []string{"!x.c", "x.c"}, Options{NoDefaultIgnores: true})
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		// .grootignore overrides the negation in .gitignore.
+		{"keep.log", true},
+		// .grootinclude forces a file git ignores back in.
+		{"app.log", false},
+		// The user's patterns have the final say, the last one winning.
+		{"x.c", true},
+		{".git", true},
+	}
+	for _, tc := range tests {
+		if got := m.Ignored(filepath.Join(root, tc.path), false); got != tc.ignored {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.path, got, tc.ignored)
+		}
+	}
+}
+
+func TestParseIgnoreRuleSkipsBlankAndComments(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "/", "!"} {
+		if _, ok := parseIgnoreRule(line); ok {
+			t.Errorf("parseIgnoreRule(%q) produced a rule", line)
+		}
+	}
+}
@@ -1,14 +1,14 @@
 package walker
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 
-	"github.com/denormal/go-gitignore"
+	"github.com/harsh-apk/groot/internal/git"
 	"github.com/harsh-apk/groot/internal/model"
 )
 
@@ -57,35 +57,42 @@ var defaultIgnorePatterns = []string{
 	"deps",
 }
 
+// Options holds the optional behaviour of a walk.
+type Options struct {
+	// NoDefaultIgnores disables the built-in defaultIgnorePatterns, leaving
+	// only git's ignore files, .grootignore files and the user's patterns.
+	NoDefaultIgnores bool
+	// GitTracked builds the tree from the files in the git index instead of
+	// walking the file system, so untracked files never appear.
+	GitTracked bool
+	// Since restricts the tree to files that changed relative to this git ref
+	// and marks each file with its change status.
+	Since string
+	// Staged compares the index instead of the working tree, against Since or HEAD.
+	Staged bool
+}
+
 // --- UPDATED FUNCTION SIGNATURE ---
-// BuildFileTree now accepts custom ignore patterns from the user.
-func BuildFileTree(rootPath string, customIgnorePatterns []string) (*model.Node, error) {
+// BuildFileTree now accepts custom ignore patterns from the user. If ctx is
+// done before the walk finishes, the tree built so far is returned together
+// with ctx's error.
+func BuildFileTree(ctx context.Context, rootPath string, customIgnorePatterns []string, opts Options) (*model.Node, error) {
 	absRoot, err := filepath.Abs(rootPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not get absolute path for '%s': %w", rootPath, err)
 	}
 
-	// --- COMBINE IGNORE PATTERNS ---
-	// Start with the comprehensive default ignore patterns.
-	patterns := defaultIgnorePatterns
-	// Append any custom patterns provided by the user via the --skip flag.
-	patterns = append(patterns, customIgnorePatterns...)
+	// Combine the default patterns, git's and groot's ignore files at every
+	// level and the user's --skip patterns into a single git-accurate matcher.
+	ignore := newIgnoreMatcher(ctx, absRoot, customIgnorePatterns, opts)
 
-	// Read and append patterns from the project's .gitignore file if it exists.
-	ignoreFilePath := filepath.Join(absRoot, ".gitignore")
-	if _, err := os.Stat(ignoreFilePath); err == nil {
-		content, err := os.ReadFile(ignoreFilePath)
-		if err == nil {
-			patterns = append(patterns, strings.Split(string(content), "\n")...)
-		}
+	entries, listed, err := listEntries(ctx, absRoot, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list files in '%s': %w", rootPath, err)
+	}
+	if listed {
+		return buildTreeFromPaths(ctx, absRoot, entries, ignore)
 	}
-
-	// Create a gitignore matcher with the combined patterns.
-	ignore := gitignore.New(
-		strings.NewReader(strings.Join(patterns, "\n")),
-		absRoot,
-		nil,
-	)
 
 	rootNode := &model.Node{Name: filepath.Base(absRoot), Path: absRoot, IsDir: true}
 	nodesByPath := map[string]*model.Node{absRoot: rootNode}
@@ -94,11 +101,14 @@ func BuildFileTree(rootPath string, customIgnorePatterns []string) (*model.Node,
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if path == absRoot {
 			return nil
 		}
 
-		if match := ignore.Match(path); match != nil && match.Ignore() {
+		if ignore.Ignored(path, d.IsDir()) {
 			if d.IsDir() {
 				return filepath.SkipDir
 			}
@@ -125,6 +135,10 @@ func BuildFileTree(rootPath string, customIgnorePatterns []string) (*model.Node,
 		return nil
 	})
 
+	if walkErr != nil && ctx.Err() != nil {
+		recursiveSort(rootNode)
+		return rootNode, walkErr
+	}
 	if walkErr != nil {
 		return nil, fmt.Errorf("error walking directory '%s': %w", rootPath, walkErr)
 	}
@@ -133,6 +147,174 @@ func BuildFileTree(rootPath string, customIgnorePatterns []string) (*model.Node,
 	return rootNode, nil
 }
 
+// Walk calls visit for every file that BuildFileTree would include, in
+// lexical path order, without keeping the tree in memory. The nodes passed to
+// visit have no parent links. The walk stops with ctx's error once ctx is done.
+func Walk(ctx context.Context, rootPath string, customIgnorePatterns []string, opts Options, visit func(*model.Node) error) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("could not get absolute path for '%s': %w", rootPath, err)
+	}
+	ignore := newIgnoreMatcher(ctx, absRoot, customIgnorePatterns, opts)
+
+	entries, listed, err := listEntries(ctx, absRoot, opts)
+	if err != nil {
+		return fmt.Errorf("could not list files in '%s': %w", rootPath, err)
+	}
+	if listed {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].relPath < entries[j].relPath })
+		return visitEntries(ctx, absRoot, entries, ignore, visit)
+	}
+
+	walkErr := filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == absRoot {
+			return nil
+		}
+		if ignore.Ignored(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return visit(&model.Node{Name: d.Name(), Path: path})
+	})
+	if walkErr != nil && ctx.Err() == nil {
+		return fmt.Errorf("error walking directory '%s': %w", rootPath, walkErr)
+	}
+	return walkErr
+}
+
+// treeEntry is a file to place in a tree built from a list of paths.
+type treeEntry struct {
+	// relPath is slash-separated and relative to the tree root.
+	relPath string
+	// status and oldPath carry the git change status, if any.
+	status  string
+	oldPath string
+	// staged files are read from the index, so they need not exist on disk.
+	staged bool
+}
+
+// listEntries returns the files to analyze when they come from git rather
+// than from walking the file system, and reports whether that is the case.
+func listEntries(ctx context.Context, absRoot string, opts Options) ([]treeEntry, bool, error) {
+	if opts.Since != "" || opts.Staged {
+		changes, err := git.ChangedFiles(ctx, absRoot, opts.Since, opts.Staged)
+		if err != nil {
+			return nil, true, fmt.Errorf("could not list changed files: %w", err)
+		}
+		entries := make([]treeEntry, 0, len(changes))
+		for _, change := range changes {
+			entries = append(entries, treeEntry{relPath: change.Path, status: change.Status, oldPath: change.OldPath, staged: opts.Staged})
+		}
+		return entries, true, nil
+	}
+
+	if opts.GitTracked {
+		relPaths, err := git.TrackedFiles(ctx, absRoot)
+		if err != nil {
+			return nil, true, fmt.Errorf("could not list git-tracked files: %w", err)
+		}
+		entries := make([]treeEntry, 0, len(relPaths))
+		for _, relPath := range relPaths {
+			entries = append(entries, treeEntry{relPath: relPath})
+		}
+		return entries, true, nil
+	}
+	return nil, false, nil
+}
+
+// buildTreeFromPaths assembles a tree from a list of files, creating
+// intermediate directories as needed. Like BuildFileTree, it returns the
+// partial tree with ctx's error when ctx is done early.
+func buildTreeFromPaths(ctx context.Context, absRoot string, entries []treeEntry, ignore *ignoreMatcher) (*model.Node, error) {
+	rootNode := &model.Node{Name: filepath.Base(absRoot), Path: absRoot, IsDir: true}
+	nodesByPath := map[string]*model.Node{absRoot: rootNode}
+	err := visitEntries(ctx, absRoot, entries, ignore, func(node *model.Node) error {
+		parent := ensureDir(filepath.Dir(node.Path), nodesByPath)
+		parent.Children = append(parent.Children, node)
+		return nil
+	})
+	recursiveSort(rootNode)
+	return rootNode, err
+}
+
+// visitEntries calls visit for each listed file once. Files that are ignored,
+// sit below an ignored directory or are not regular files on disk are left
+// out, except deleted files, which are kept so their change can be reported,
+// and staged files, whose content comes from the index.
+func visitEntries(ctx context.Context, absRoot string, entries []treeEntry, ignore *ignoreMatcher, visit func(*model.Node) error) error {
+	seen := make(map[string]bool)
+	ignoredDirs := make(map[string]bool)
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		path := filepath.Join(absRoot, filepath.FromSlash(entry.relPath))
+		if seen[path] {
+			continue
+		}
+		if entry.status != git.StatusDeleted {
+			info, err := os.Lstat(path)
+			missing := err != nil && !entry.staged
+			if missing || (err == nil && info.IsDir()) {
+				// Missing files and submodules have no file content to analyze.
+				continue
+			}
+		}
+		if ignore.Ignored(path, false) || parentIgnored(absRoot, path, ignore, ignoredDirs) {
+			continue
+		}
+		seen[path] = true
+
+		node := &model.Node{Name: filepath.Base(path), Path: path, Status: entry.status}
+		if entry.oldPath != "" {
+			node.OldPath = filepath.Join(absRoot, filepath.FromSlash(entry.oldPath))
+		}
+		if err := visit(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentIgnored reports whether any directory between absRoot and path is
+// ignored, caching the answer for each directory.
+func parentIgnored(absRoot, path string, ignore *ignoreMatcher, cache map[string]bool) bool {
+	dir := filepath.Dir(path)
+	if dir == absRoot || len(dir) < len(absRoot) {
+		return false
+	}
+	ignored, ok := cache[dir]
+	if !ok {
+		ignored = ignore.Ignored(dir, true) || parentIgnored(absRoot, dir, ignore, cache)
+		cache[dir] = ignored
+	}
+	return ignored
+}
+
+// ensureDir returns the directory node for path, creating it and any missing
+// ancestors. The root must already be present in nodesByPath.
+func ensureDir(path string, nodesByPath map[string]*model.Node) *model.Node {
+	if node, ok := nodesByPath[path]; ok {
+		return node
+	}
+	node := &model.Node{Name: filepath.Base(path), Path: path, IsDir: true}
+	nodesByPath[path] = node
+	parent := ensureDir(filepath.Dir(path), nodesByPath)
+	parent.Children = append(parent.Children, node)
+	return node
+}
+
 // recursiveSort sorts the children of a Node alphabetically, ensuring that
 // directories are always listed before files at the same level.
 func recursiveSort(node *model.Node) {
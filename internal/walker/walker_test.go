@@ -0,0 +1,100 @@
+package walker
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/harsh-apk/groot/internal/git"
+	"github.com/harsh-apk/groot/internal/model"
+)
+
+// gitRepo creates a repository with one committed file and returns its root.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, ".config"))
+	writeFixtureFile(t, filepath.Join(root, "kept.go"), "package main\n")
+	runGit(t, root, "init", "-q")
+	runGit(t, root, "add", ".")
+	runGit(t, root, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init")
+	return root
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// walkStatuses returns the change status of each file a walk visits, by
+// relative path.
+func walkStatuses(t *testing.T, root string, opts Options) map[string]string {
+	t.Helper()
+	statuses := make(map[string]string)
+	err := Walk(context.Background(), root, nil, opts, func(node *model.Node) error {
+		relPath, _ := filepath.Rel(root, node.Path)
+		statuses[filepath.ToSlash(relPath)] = node.Status
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statuses
+}
+
+func TestWalkStagedKeepsFilesDeletedFromWorktree(t *testing.T) {
+	root := gitRepo(t)
+	writeFixtureFile(t, filepath.Join(root, "added.go"), "package main\n")
+	writeFixtureFile(t, filepath.Join(root, "kept.go"), "package main\n\nfunc main() {}\n")
+	runGit(t, root, "add", ".")
+	// Both staged files are then removed from the working tree only.
+	for _, name := range []string{"added.go", "kept.go"} {
+		if err := os.Remove(filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := walkStatuses(t, root, Options{Staged: true})
+	want := map[string]string{"added.go": git.StatusAdded, "kept.go": git.StatusModified}
+	for path, status := range want {
+		if got[path] != status {
+			t.Errorf("staged walk: %s has status %q, want %q (visited %v)", path, got[path], status, got)
+		}
+	}
+
+	// Comparing the working tree, the same files are gone.
+	got = walkStatuses(t, root, Options{Since: "HEAD"})
+	if _, ok := got["added.go"]; ok {
+		t.Errorf("worktree walk visited added.go, which exists in neither HEAD nor the working tree")
+	}
+	if got["kept.go"] != git.StatusDeleted {
+		t.Errorf("worktree walk: kept.go has status %q, want %q", got["kept.go"], git.StatusDeleted)
+	}
+}
+
+func TestWalkGitTrackedAppliesDefaultIgnores(t *testing.T) {
+	root := gitRepo(t)
+	writeFixtureFile(t, filepath.Join(root, "vendor", "x", "x.go"), "package x\n")
+	writeFixtureFile(t, filepath.Join(root, "build", "b.go"), "package build\n")
+	runGit(t, root, "add", ".")
+	runGit(t, root, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "vendor")
+
+	for _, opts := range []Options{{}, {GitTracked: true}} {
+		got := walkStatuses(t, root, opts)
+		if _, ok := got["kept.go"]; !ok || len(got) != 1 {
+			t.Errorf("GitTracked=%v: visited %v, want only kept.go", opts.GitTracked, got)
+		}
+	}
+	got := walkStatuses(t, root, Options{GitTracked: true, NoDefaultIgnores: true})
+	if _, ok := got["vendor/x/x.go"]; !ok {
+		t.Errorf("GitTracked without default ignores: visited %v, want vendor/x/x.go", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Package watch reports changes to the files of a set of directories.
+package watch
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrOverflow is sent on Errors when changes were lost, after which callers
+// should assume any file may have changed.
+var ErrOverflow = errors.New("file system event queue overflowed")
+
+// Watcher reports entries created, modified, removed or renamed inside a set
+// of watched directories. Subdirectories are not watched implicitly; callers
+// pass every directory they care about to Sync, typically after each walk.
+type Watcher struct {
+	// Events receives the absolute path of every changed entry.
+	Events chan string
+	// Errors receives problems that do not stop the watcher.
+	Errors chan error
+
+	mu   sync.Mutex
+	dirs map[string]bool
+	sys  *platform
+	done chan struct{}
+}
+
+// New starts a watcher with no directories.
+func New() (*Watcher, error) {
+	w := &Watcher{
+		Events: make(chan string, 128),
+		Errors: make(chan error, 8),
+		dirs:   make(map[string]bool),
+		done:   make(chan struct{}),
+	}
+	sys, err := newPlatform(w)
+	if err != nil {
+		return nil, err
+	}
+	w.sys = sys
+	return w, nil
+}
+
+// Sync makes dirs the set of watched directories, watching the new ones and
+// dropping those no longer listed.
+func (w *Watcher) Sync(dirs []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wanted := make(map[string]bool, len(dirs))
+	var errs []error
+	for _, dir := range dirs {
+		wanted[dir] = true
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.sys.add(dir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		w.dirs[dir] = true
+	}
+	for dir := range w.dirs {
+		if !wanted[dir] {
+			w.sys.remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the watcher. Events and Errors are not closed.
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.sys.close()
+}
+
+// send delivers a changed path unless the watcher is closing.
+func (w *Watcher) send(path string) {
+	select {
+	case w.Events <- path:
+	case <-w.done:
+	}
+}
+
+// fail reports a non-fatal error without blocking the event loop.
+func (w *Watcher) fail(err error) {
+	select {
+	case w.Errors <- err:
+	default:
+	}
+}
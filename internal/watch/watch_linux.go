@@ -0,0 +1,113 @@
+//go:build linux
+
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchMask selects the inotify events that can change an analysis.
+const watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// platform watches directories with inotify.
+type platform struct {
+	w    *Watcher
+	file *os.File
+	// wds maps watch descriptors to directories and back; guarded by w.mu.
+	wds   map[int]string
+	byDir map[string]int
+}
+
+func newPlatform(w *Watcher) (*platform, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize inotify: %w", err)
+	}
+	p := &platform{
+		w:     w,
+		file:  os.NewFile(uintptr(fd), "inotify"),
+		wds:   make(map[int]string),
+		byDir: make(map[string]int),
+	}
+	go p.readEvents()
+	return p, nil
+}
+
+func (p *platform) add(dir string) error {
+	wd, err := unix.InotifyAddWatch(int(p.file.Fd()), dir, watchMask)
+	if err != nil {
+		return fmt.Errorf("could not watch %s: %w", dir, err)
+	}
+	p.wds[wd] = dir
+	p.byDir[dir] = wd
+	return nil
+}
+
+func (p *platform) remove(dir string) {
+	wd, ok := p.byDir[dir]
+	if !ok {
+		return
+	}
+	// The kernel drops the watch by itself when the directory is deleted.
+	_, _ = unix.InotifyRmWatch(int(p.file.Fd()), uint32(wd))
+	delete(p.wds, wd)
+	delete(p.byDir, dir)
+}
+
+func (p *platform) close() error {
+	return p.file.Close()
+}
+
+// readEvents decodes inotify events until the watcher is closed.
+func (p *platform) readEvents() {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := p.file.Read(buf)
+		if err != nil {
+			select {
+			case <-p.w.done:
+			default:
+				p.w.fail(fmt.Errorf("could not read file system events: %w", err))
+			}
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+int(event.Len)], "\x00"))
+			offset = nameStart + int(event.Len)
+			p.handle(int(event.Wd), event.Mask, name)
+		}
+	}
+}
+
+// handle translates a single event into a changed path.
+func (p *platform) handle(wd int, mask uint32, name string) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		p.w.fail(ErrOverflow)
+		return
+	}
+	p.w.mu.Lock()
+	dir, ok := p.wds[wd]
+	if ok && mask&unix.IN_IGNORED != 0 {
+		delete(p.wds, wd)
+		delete(p.byDir, dir)
+		delete(p.w.dirs, dir)
+	}
+	p.w.mu.Unlock()
+	if !ok || mask&unix.IN_IGNORED != 0 {
+		return
+	}
+	if name == "" {
+		p.w.send(dir)
+		return
+	}
+	p.w.send(filepath.Join(dir, name))
+}
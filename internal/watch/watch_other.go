@@ -0,0 +1,119 @@
+//go:build !linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often watched directories are rescanned.
+const pollInterval = time.Second
+
+// entryState is what polling compares to detect a change.
+type entryState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// platform watches directories by periodically listing them, for systems
+// without inotify.
+type platform struct {
+	w *Watcher
+	// snapshots holds the last listing of each directory; guarded by w.mu.
+	snapshots map[string]map[string]entryState
+}
+
+func newPlatform(w *Watcher) (*platform, error) {
+	p := &platform{w: w, snapshots: make(map[string]map[string]entryState)}
+	go p.poll()
+	return p, nil
+}
+
+func (p *platform) add(dir string) error {
+	snapshot, err := listDir(dir)
+	if err != nil {
+		return err
+	}
+	p.snapshots[dir] = snapshot
+	return nil
+}
+
+func (p *platform) remove(dir string) {
+	delete(p.snapshots, dir)
+}
+
+func (p *platform) close() error {
+	return nil
+}
+
+// poll rescans the watched directories until the watcher is closed.
+func (p *platform) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.w.done:
+			return
+		case <-ticker.C:
+		}
+		for _, path := range p.scan() {
+			p.w.send(path)
+		}
+	}
+}
+
+// scan lists every watched directory and returns the entries that differ
+// from the previous listing.
+func (p *platform) scan() []string {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+
+	var changed []string
+	for dir, old := range p.snapshots {
+		current, err := listDir(dir)
+		if err != nil {
+			changed = append(changed, dir)
+			delete(p.snapshots, dir)
+			delete(p.w.dirs, dir)
+			continue
+		}
+		for name, state := range current {
+			if prev, ok := old[name]; !ok || prev != state {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		for name := range old {
+			if _, ok := current[name]; !ok {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		p.snapshots[dir] = current
+	}
+	return changed
+}
+
+// listDir records the state of every entry of dir.
+func listDir(dir string) (map[string]entryState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]entryState, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state := entryState{modTime: info.ModTime(), size: info.Size(), isDir: entry.IsDir()}
+		if state.isDir {
+			// A directory's own metadata changes with its entries, which are
+			// reported through their own listing.
+			state.modTime, state.size = time.Time{}, 0
+		}
+		snapshot[entry.Name()] = state
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitFor returns once the watcher reports path, failing after a timeout
+// long enough for the polling implementation.
+func waitFor(t *testing.T, w *Watcher, path string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-w.Events:
+			if got == path {
+				return
+			}
+		case err := <-w.Errors:
+			t.Fatalf("watcher error: %v", err)
+		case <-timeout:
+			t.Fatalf("no event for %s", path)
+		}
+	}
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Sync([]string{dir, sub}); err != nil {
+		t.Fatal(err)
+	}
+
+	created := filepath.Join(sub, "a.go")
+	if err := os.WriteFile(created, []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, created)
+
+	removed := filepath.Join(dir, "b.go")
+	if err := os.WriteFile(removed, []byte("package b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, removed)
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, removed)
+}
+
+func TestSyncDropsDirectories(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Sync([]string{dir, sub}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Sync([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	w.mu.Lock()
+	watched := w.dirs[sub]
+	w.mu.Unlock()
+	if watched {
+		t.Errorf("%s is still watched after Sync dropped it", sub)
+	}
+	if err := w.Sync([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Errorf("Sync of a missing directory succeeded")
+	}
+}